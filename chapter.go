@@ -0,0 +1,345 @@
+package id3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	tocFlagOrdered  byte = 0x01
+	tocFlagTopLevel byte = 0x02
+
+	noChapterOffset uint32 = 0xFFFFFFFF
+)
+
+// subFrameParams looks up the frame tables for reading sub-frames. It is
+// assigned in init, as the tables refer back to the chapter frames.
+var subFrameParams func(version uint8) (*versionParams, error)
+
+func init() {
+	subFrameParams = paramsForVersion
+}
+
+// embeddedFrames are the sub-frames of a chapter or table of contents, such
+// as its title, link and image.
+type embeddedFrames struct {
+	version uint8
+	frames  []Frame
+}
+
+func readEmbeddedFrames(tag *Tag, data []byte) embeddedFrames {
+	header := &Header{version: 4}
+	if tag != nil && tag.Header != nil {
+		// Copied so that padding found here does not change the tag. The
+		// frame data was already resynchronised, so the copy is not
+		// unsynchronised.
+		h := *tag.Header
+		h.flags &^= 0x80
+		header = &h
+	}
+	ef := embeddedFrames{version: header.version}
+	if len(data) == 0 {
+		return ef
+	}
+	params, err := subFrameParams(header.version)
+	if err != nil {
+		glog.Errorf("Error reading sub-frames: %v", err)
+		return ef
+	}
+	sub := newTag(header, nil)
+	err = sub.readV2(uint32(len(data)), params, bytes.NewReader(data))
+	if err != nil {
+		glog.Errorf("Error reading sub-frames: %v", err)
+	}
+	ef.frames = sub.frames
+	return ef
+}
+
+func (ef *embeddedFrames) SubFrames() []Frame {
+	frames := make([]Frame, len(ef.frames))
+	copy(frames, ef.frames)
+	return frames
+}
+
+// SubFrame returns the first sub-frame of the given kind, or nil.
+func (ef *embeddedFrames) SubFrame(kind FrameKind) Frame {
+	kind = frameKindOf(string(kind))
+	for _, frame := range ef.frames {
+		if frameKindOf(frame.Id()) == kind {
+			return frame
+		}
+	}
+	return nil
+}
+
+func (ef *embeddedFrames) AddSubFrame(frame Frame) {
+	ef.frames = append(ef.frames, frame)
+}
+
+// Title returns the text of the TIT2 sub-frame.
+func (ef *embeddedFrames) Title() string {
+	if frame := ef.SubFrame(FrameTitle); frame != nil {
+		return frame.String()
+	}
+	return ""
+}
+
+func (ef *embeddedFrames) encodeSubFrames(b []byte, version uint8) ([]byte, error) {
+	params, err := paramsForVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return writeFrames(b, ef.frames, ef.version, version, params)
+}
+
+// ChapterFrame is a CHAP frame, marking a section of the audio by time and
+// optionally by byte offset.
+type ChapterFrame struct {
+	frameBase
+	embeddedFrames
+
+	elementID   string
+	startTime   uint32
+	endTime     uint32
+	startOffset uint32
+	endOffset   uint32
+}
+
+func newChapterFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	cf := &ChapterFrame{}
+	cf.header = header
+
+	elementID, i, err := readLatin1String(data)
+	if err != nil {
+		return nil, err
+	}
+	if i+16 > len(data) {
+		return nil, ErrTooShort
+	}
+	cf.elementID = elementID
+	cf.startTime = binary.BigEndian.Uint32(data[i:])
+	cf.endTime = binary.BigEndian.Uint32(data[i+4:])
+	cf.startOffset = binary.BigEndian.Uint32(data[i+8:])
+	cf.endOffset = binary.BigEndian.Uint32(data[i+12:])
+	cf.embeddedFrames = readEmbeddedFrames(tag, data[i+16:])
+	return cf, nil
+}
+
+func NewChapterFrame(id string, elementID string, start time.Duration, end time.Duration, subFrames ...Frame) *ChapterFrame {
+	cf := &ChapterFrame{}
+	cf.header = newFrameHeader(id, 0, 0, 0)
+	cf.elementID = elementID
+	cf.startTime = uint32(start / time.Millisecond)
+	cf.endTime = uint32(end / time.Millisecond)
+	cf.startOffset = noChapterOffset
+	cf.endOffset = noChapterOffset
+	cf.embeddedFrames = embeddedFrames{version: 4, frames: subFrames}
+	return cf
+}
+
+func (cf *ChapterFrame) ElementID() string {
+	return cf.elementID
+}
+
+func (cf *ChapterFrame) StartTime() time.Duration {
+	return time.Duration(cf.startTime) * time.Millisecond
+}
+
+func (cf *ChapterFrame) EndTime() time.Duration {
+	return time.Duration(cf.endTime) * time.Millisecond
+}
+
+// StartOffset returns the byte offset of the start of the chapter from the
+// beginning of the file, if the chapter has one.
+func (cf *ChapterFrame) StartOffset() (uint32, bool) {
+	return cf.startOffset, cf.startOffset != noChapterOffset
+}
+
+func (cf *ChapterFrame) EndOffset() (uint32, bool) {
+	return cf.endOffset, cf.endOffset != noChapterOffset
+}
+
+func (cf *ChapterFrame) String() string {
+	return fmt.Sprintf("%v: %v (%v-%v)", cf.elementID, cf.Title(), cf.StartTime(), cf.EndTime())
+}
+
+func (cf *ChapterFrame) Bytes() []byte {
+	b, _ := cf.encode(cf.version)
+	return b
+}
+
+func (cf *ChapterFrame) encode(version uint8) ([]byte, error) {
+	b, err := appendString(nil, cf.elementID, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range []uint32{cf.startTime, cf.endTime, cf.startOffset, cf.endOffset} {
+		b = append(b, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], n)
+	}
+	return cf.encodeSubFrames(b, version)
+}
+
+// TableOfContentsFrame is a CTOC frame, listing the chapters, or further
+// tables of contents, that make up a part of the audio.
+type TableOfContentsFrame struct {
+	frameBase
+	embeddedFrames
+
+	elementID string
+	flags     byte
+	children  []string
+}
+
+func newTableOfContentsFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	tf := &TableOfContentsFrame{}
+	tf.header = header
+
+	elementID, i, err := readLatin1String(data)
+	if err != nil {
+		return nil, err
+	}
+	if i+2 > len(data) {
+		return nil, ErrTooShort
+	}
+	tf.elementID = elementID
+	tf.flags = data[i]
+	count := int(data[i+1])
+	i += 2
+	for n := 0; n < count; n++ {
+		child, j, err := readLatin1String(data[i:])
+		if err != nil {
+			return nil, err
+		}
+		tf.children = append(tf.children, child)
+		i += j
+	}
+	tf.embeddedFrames = readEmbeddedFrames(tag, data[i:])
+	return tf, nil
+}
+
+func NewTableOfContentsFrame(id string, elementID string, topLevel bool, ordered bool, children []string, subFrames ...Frame) *TableOfContentsFrame {
+	tf := &TableOfContentsFrame{}
+	tf.header = newFrameHeader(id, 0, 0, 0)
+	tf.elementID = elementID
+	if topLevel {
+		tf.flags |= tocFlagTopLevel
+	}
+	if ordered {
+		tf.flags |= tocFlagOrdered
+	}
+	tf.children = children
+	tf.embeddedFrames = embeddedFrames{version: 4, frames: subFrames}
+	return tf
+}
+
+func (tf *TableOfContentsFrame) ElementID() string {
+	return tf.elementID
+}
+
+// TopLevel reports whether this is the root of the table of contents.
+func (tf *TableOfContentsFrame) TopLevel() bool {
+	return tf.flags&tocFlagTopLevel != 0
+}
+
+// Ordered reports whether the children are listed in playback order.
+func (tf *TableOfContentsFrame) Ordered() bool {
+	return tf.flags&tocFlagOrdered != 0
+}
+
+func (tf *TableOfContentsFrame) ChildElementIDs() []string {
+	children := make([]string, len(tf.children))
+	copy(children, tf.children)
+	return children
+}
+
+func (tf *TableOfContentsFrame) String() string {
+	return fmt.Sprintf("%v: %v %v", tf.elementID, tf.Title(), tf.children)
+}
+
+func (tf *TableOfContentsFrame) Bytes() []byte {
+	b, _ := tf.encode(tf.version)
+	return b
+}
+
+func (tf *TableOfContentsFrame) encode(version uint8) ([]byte, error) {
+	if len(tf.children) > 0xFF {
+		return nil, ErrTooLarge
+	}
+	b, err := appendString(nil, tf.elementID, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, tf.flags, byte(len(tf.children)))
+	for _, child := range tf.children {
+		b, err = appendString(b, child, ISO88591, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tf.encodeSubFrames(b, version)
+}
+
+// TableOfContents returns the top-level CTOC frame, or nil.
+func (tag *Tag) TableOfContents() *TableOfContentsFrame {
+	for _, frame := range tag.framesOf(FrameTableOfContents) {
+		if tf, ok := frame.(*TableOfContentsFrame); ok && tf.TopLevel() {
+			return tf
+		}
+	}
+	return nil
+}
+
+// Chapters returns the tag's chapters in playback order. The order of an
+// ordered top-level table of contents is followed, including any nested
+// tables; otherwise chapters are sorted by start time.
+func (tag *Tag) Chapters() []*ChapterFrame {
+	var chapters []*ChapterFrame
+	byElement := make(map[string]Frame)
+	for _, frame := range tag.framesOf(FrameChapter) {
+		if cf, ok := frame.(*ChapterFrame); ok {
+			chapters = append(chapters, cf)
+			byElement[cf.ElementID()] = cf
+		}
+	}
+	for _, frame := range tag.framesOf(FrameTableOfContents) {
+		if tf, ok := frame.(*TableOfContentsFrame); ok {
+			byElement[tf.ElementID()] = tf
+		}
+	}
+
+	if toc := tag.TableOfContents(); toc != nil && toc.Ordered() {
+		var ordered []*ChapterFrame
+		seen := make(map[string]bool)
+		var walk func(tf *TableOfContentsFrame)
+		walk = func(tf *TableOfContentsFrame) {
+			seen[tf.ElementID()] = true
+			for _, child := range tf.children {
+				if seen[child] {
+					continue
+				}
+				switch f := byElement[child].(type) {
+				case *ChapterFrame:
+					seen[child] = true
+					ordered = append(ordered, f)
+				case *TableOfContentsFrame:
+					walk(f)
+				}
+			}
+		}
+		walk(toc)
+		if len(ordered) == len(chapters) {
+			return ordered
+		}
+	}
+
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].startTime < chapters[j].startTime
+	})
+	return chapters
+}
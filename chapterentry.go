@@ -0,0 +1,310 @@
+package id3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChapterEntry describes a chapter independently of the frames that store
+// it, for authoring chapters and converting them to other formats.
+type ChapterEntry struct {
+	Start time.Duration
+	// End may be left zero to end the chapter where the next one starts
+	End   time.Duration
+	Title string
+	URL   string
+	// Image holds the picture data, or its URL if ImageMIME is PictureLinkMIME
+	Image     []byte
+	ImageMIME string
+}
+
+// ChapterEntries returns the tag's chapters in playback order.
+func (tag *Tag) ChapterEntries() []ChapterEntry {
+	var entries []ChapterEntry
+	for _, cf := range tag.Chapters() {
+		entry := ChapterEntry{
+			Start: cf.StartTime(),
+			End:   cf.EndTime(),
+			Title: cf.Title(),
+		}
+		switch f := cf.SubFrame(FrameUserURL).(type) {
+		case *UserURLFrame:
+			entry.URL = f.URL()
+		}
+		switch f := cf.SubFrame(FrameAttachedPicture).(type) {
+		case *PictureFrame:
+			entry.Image = f.Bytes()
+			entry.ImageMIME = f.MIMEType()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// SetChapters replaces the tag's chapters and tables of contents with the
+// given entries, listed in order by a single top-level table of contents.
+// Entries must be sorted by start time; an entry without an end time ends
+// where the next one starts, or at its own start if it is the last.
+func (tag *Tag) SetChapters(entries []ChapterEntry) error {
+	if len(entries) > 0xFF {
+		return ErrTooManyChapters
+	}
+	version := tag.version()
+	if version < 3 {
+		return errors.New(fmt.Sprintf("Chapters cannot be written to a v2.%v tag", version))
+	}
+	var chapters []Frame
+	var children []string
+	for i, entry := range entries {
+		end := entry.End
+		if end == 0 {
+			end = entry.Start
+			if i+1 < len(entries) {
+				end = entries[i+1].Start
+			}
+		}
+		if end < entry.Start || i > 0 && entry.Start < entries[i-1].Start {
+			return ErrChapterOrder
+		}
+		var subFrames []Frame
+		if entry.Title != "" {
+			subFrames = append(subFrames, NewTextFrame(FrameTitle.ID(version), entry.Title))
+		}
+		if entry.URL != "" {
+			subFrames = append(subFrames, NewUserURLFrame(FrameUserURL.ID(version), "", entry.URL))
+		}
+		if len(entry.Image) > 0 {
+			subFrames = append(subFrames, NewPictureFrame(FrameAttachedPicture.ID(version), entry.ImageMIME, PictureTypeOther, "", entry.Image))
+		}
+		elementID := fmt.Sprintf("chp%v", i)
+		children = append(children, elementID)
+		chapters = append(chapters, NewChapterFrame(FrameChapter.ID(version), elementID, entry.Start, end, subFrames...))
+	}
+
+	tag.RemoveFrames(FrameChapter)
+	tag.RemoveFrames(FrameTableOfContents)
+	if len(entries) == 0 {
+		return nil
+	}
+	tag.addFrame(NewTableOfContentsFrame(FrameTableOfContents.ID(version), "toc", true, true, children))
+	for _, frame := range chapters {
+		tag.addFrame(frame)
+	}
+	return nil
+}
+
+// podloveChapter is a chapter in Podlove Simple Chapters JSON.
+type podloveChapter struct {
+	Start string `json:"start"`
+	Title string `json:"title"`
+	Href  string `json:"href,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+// ParsePodloveJSON reads chapters in the JSON form of Podlove Simple
+// Chapters. Images are URLs, so they are returned as picture links.
+func ParsePodloveJSON(data []byte) ([]ChapterEntry, error) {
+	var chapters []podloveChapter
+	err := json.Unmarshal(data, &chapters)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ChapterEntry
+	for _, c := range chapters {
+		start, err := parseNormalPlayTime(c.Start)
+		if err != nil {
+			return nil, err
+		}
+		entry := ChapterEntry{Start: start, Title: c.Title, URL: c.Href}
+		if c.Image != "" {
+			entry.Image = []byte(c.Image)
+			entry.ImageMIME = PictureLinkMIME
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// FormatPodloveJSON writes chapters as Podlove Simple Chapters JSON. Only
+// images that are picture links can be included.
+func FormatPodloveJSON(entries []ChapterEntry) ([]byte, error) {
+	chapters := []podloveChapter{}
+	for _, entry := range entries {
+		c := podloveChapter{
+			Start: formatNormalPlayTime(entry.Start),
+			Title: entry.Title,
+			Href:  entry.URL,
+		}
+		if entry.ImageMIME == PictureLinkMIME {
+			c.Image = string(entry.Image)
+		}
+		chapters = append(chapters, c)
+	}
+	return json.MarshalIndent(chapters, "", "  ")
+}
+
+// parseNormalPlayTime parses the HH:MM:SS.mmm times used by Podlove Simple
+// Chapters, where the hours, minutes and fraction are optional.
+func parseNormalPlayTime(s string) (time.Duration, error) {
+	invalid := errors.New(fmt.Sprintf("invalid chapter time: %q", s))
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, invalid
+	}
+	var d time.Duration
+	for i, part := range parts {
+		if i < len(parts)-1 {
+			n, err := strconv.ParseUint(part, 10, 32)
+			if err != nil {
+				return 0, invalid
+			}
+			d = (d + time.Duration(n)) * 60
+			continue
+		}
+		seconds, err := strconv.ParseFloat(part, 64)
+		if err != nil || seconds < 0 {
+			return 0, invalid
+		}
+		d = d*time.Second + time.Duration(seconds*1000+0.5)*time.Millisecond
+	}
+	return d, nil
+}
+
+func formatNormalPlayTime(d time.Duration) string {
+	ms := d / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// ParseFFMetadata reads the chapters from an FFmpeg metadata file. Global
+// and stream metadata are ignored.
+func ParseFFMetadata(r io.Reader) ([]ChapterEntry, error) {
+	var entries []ChapterEntry
+	var chapter *ChapterEntry
+	var start, end int64
+	num, den := int64(1), int64(1000000000)
+	finish := func() {
+		if chapter != nil {
+			chapter.Start = timebaseDuration(start, num, den)
+			chapter.End = timebaseDuration(end, num, den)
+			entries = append(entries, *chapter)
+		}
+		chapter = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		// A trailing backslash escapes the newline
+		for strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") && scanner.Scan() {
+			line = line[:len(line)-1] + "\n" + scanner.Text()
+		}
+		if first {
+			first = false
+			if !strings.HasPrefix(line, ";FFMETADATA") {
+				return nil, ErrFFMetadata
+			}
+			continue
+		}
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			finish()
+			if strings.TrimSpace(line) == "[CHAPTER]" {
+				chapter = &ChapterEntry{}
+				start, end = 0, 0
+				num, den = 1, 1000000000
+			}
+			continue
+		}
+		if chapter == nil {
+			continue
+		}
+		key, value := splitFFMetadata(line)
+		var err error
+		switch strings.ToUpper(key) {
+		case "TIMEBASE":
+			parts := strings.Split(value, "/")
+			if len(parts) != 2 {
+				return nil, ErrFFMetadata
+			}
+			num, err = strconv.ParseInt(parts[0], 10, 64)
+			if err == nil {
+				den, err = strconv.ParseInt(parts[1], 10, 64)
+			}
+			if err == nil && (num <= 0 || den <= 0) {
+				err = ErrFFMetadata
+			}
+		case "START":
+			start, err = strconv.ParseInt(value, 10, 64)
+		case "END":
+			end, err = strconv.ParseInt(value, 10, 64)
+		case "TITLE":
+			chapter.Title = value
+		}
+		if err != nil {
+			return nil, ErrFFMetadata
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if first {
+		return nil, ErrFFMetadata
+	}
+	finish()
+	return entries, nil
+}
+
+func timebaseDuration(n int64, num int64, den int64) time.Duration {
+	return time.Duration(float64(n)*float64(num)/float64(den)*float64(time.Second) + 0.5)
+}
+
+// splitFFMetadata splits a key=value line at the first unescaped '=' and
+// removes the escaping from both halves.
+func splitFFMetadata(line string) (string, string) {
+	var key, value bytes.Buffer
+	current := &key
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			i++
+			current.WriteByte(line[i])
+		case line[i] == '=' && current == &key:
+			current = &value
+		default:
+			current.WriteByte(line[i])
+		}
+	}
+	return key.String(), value.String()
+}
+
+// FormatFFMetadata writes chapters as an FFmpeg metadata file with
+// millisecond timestamps.
+func FormatFFMetadata(entries []ChapterEntry) string {
+	escape := strings.NewReplacer("\\", "\\\\", "=", "\\=", ";", "\\;", "#", "\\#", "\n", "\\\n")
+	var b bytes.Buffer
+	b.WriteString(";FFMETADATA1\n")
+	for i, entry := range entries {
+		end := entry.End
+		if end == 0 {
+			end = entry.Start
+			if i+1 < len(entries) {
+				end = entries[i+1].Start
+			}
+		}
+		fmt.Fprintf(&b, "\n[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\n", entry.Start/time.Millisecond, end/time.Millisecond)
+		if entry.Title != "" {
+			fmt.Fprintf(&b, "title=%v\n", escape.Replace(entry.Title))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,419 @@
+package id3
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/language"
+)
+
+// Price is an amount in the currency with the given ISO 4217 code, such as
+// "USD" and "9.99". The amount uses '.' as its decimal separator.
+type Price struct {
+	Currency string
+	Amount   string
+}
+
+func (p Price) String() string {
+	return p.Currency + p.Amount
+}
+
+func parsePrice(s string) Price {
+	if len(s) < 3 {
+		return Price{Amount: s}
+	}
+	return Price{Currency: s[:3], Amount: s[3:]}
+}
+
+// ReceivedAs describes how the audio of a COMR frame is delivered.
+type ReceivedAs byte
+
+const (
+	ReceivedAsOther ReceivedAs = iota
+	ReceivedAsStandardCDAlbum
+	ReceivedAsCompressedAudioOnCD
+	ReceivedAsFileOverInternet
+	ReceivedAsStreamOverInternet
+	ReceivedAsNoteSheets
+	ReceivedAsNoteSheetsInBook
+	ReceivedAsMusicOnOtherMedia
+	ReceivedAsNonMusicalMerchandise
+)
+
+const commerceDateFormat = "20060102"
+
+// readCommerceDate reads the YYYYMMDD dates of COMR and OWNE frames. Dates
+// that cannot be parsed are returned as the zero time.
+func readCommerceDate(data []byte) time.Time {
+	t, err := time.Parse(commerceDateFormat, string(data))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func commerceDate(t time.Time) string {
+	if t.IsZero() {
+		return "00000000"
+	}
+	return t.Format(commerceDateFormat)
+}
+
+// CommercialFrame is a COMR frame, offering the audio for sale.
+type CommercialFrame struct {
+	frameBase
+
+	prices      []Price
+	validUntil  time.Time
+	contactURL  string
+	receivedAs  ReceivedAs
+	seller      string
+	description string
+	logoMIME    string
+	logo        []byte
+}
+
+func newCommercialFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	cf := &CommercialFrame{}
+	cf.header = header
+
+	l := len(data)
+	if l < 1 {
+		return nil, ErrTooShort
+	}
+	textEncoding, encoding, err := extractEncoding(l, data)
+	if err != nil {
+		return nil, err
+	}
+	prices, i, err := readLatin1String(data[1:])
+	if err != nil {
+		return nil, err
+	}
+	i++
+	for _, price := range strings.Split(prices, "/") {
+		if price != "" {
+			cf.prices = append(cf.prices, parsePrice(price))
+		}
+	}
+	if i+8 > l {
+		return nil, ErrTooShort
+	}
+	cf.validUntil = readCommerceDate(data[i : i+8])
+	i += 8
+	contactURL, j, err := readLatin1String(data[i:])
+	if err != nil {
+		return nil, err
+	}
+	cf.contactURL = contactURL
+	i += j
+	if i >= l {
+		return nil, ErrTooShort
+	}
+	cf.receivedAs = ReceivedAs(data[i])
+	i++
+	for _, s := range []*string{&cf.seller, &cf.description} {
+		text, j, err := trimForEncoding(l-i, data[i:], textEncoding, false)
+		if err != nil {
+			return nil, err
+		}
+		*s, err = decodeString(text, encoding)
+		if err != nil {
+			return nil, err
+		}
+		i += j
+		if i >= l {
+			return cf, nil
+		}
+	}
+	// The seller logo is optional
+	mime, j, err := trimForEncoding(l-i, data[i:], ISO88591, false)
+	if err != nil {
+		return nil, err
+	}
+	cf.logoMIME, err = decodeString(mime, charmap.Windows1252)
+	if err != nil {
+		return nil, err
+	}
+	if i+j < l {
+		cf.logo = data[i+j:]
+	}
+	return cf, nil
+}
+
+func NewCommercialFrame(id string, prices []Price, validUntil time.Time, contactURL string, receivedAs ReceivedAs, seller string, description string) *CommercialFrame {
+	cf := &CommercialFrame{}
+	cf.header = newFrameHeader(id, 0, 0, 0)
+	cf.prices = prices
+	cf.validUntil = validUntil
+	cf.contactURL = contactURL
+	cf.receivedAs = receivedAs
+	cf.seller = seller
+	cf.description = description
+	return cf
+}
+
+func (cf *CommercialFrame) Prices() []Price {
+	prices := make([]Price, len(cf.prices))
+	copy(prices, cf.prices)
+	return prices
+}
+
+// ValidUntil returns the date the prices are valid until, or the zero time
+// if the frame has no valid date.
+func (cf *CommercialFrame) ValidUntil() time.Time {
+	return cf.validUntil
+}
+
+func (cf *CommercialFrame) ContactURL() string {
+	return cf.contactURL
+}
+
+func (cf *CommercialFrame) ReceivedAs() ReceivedAs {
+	return cf.receivedAs
+}
+
+func (cf *CommercialFrame) Seller() string {
+	return cf.seller
+}
+
+func (cf *CommercialFrame) Description() string {
+	return cf.description
+}
+
+// SellerLogo returns the MIME type and data of the seller's logo, which is
+// empty if the frame has none.
+func (cf *CommercialFrame) SellerLogo() (string, []byte) {
+	return cf.logoMIME, cf.logo
+}
+
+func (cf *CommercialFrame) SetSellerLogo(mime string, data []byte) {
+	cf.logoMIME = mime
+	cf.logo = data
+}
+
+func (cf *CommercialFrame) String() string {
+	return fmt.Sprintf("%v from %v", cf.description, cf.seller)
+}
+
+func (cf *CommercialFrame) Bytes() []byte {
+	return cf.logo
+}
+
+func (cf *CommercialFrame) encode(version uint8) ([]byte, error) {
+	var prices []string
+	for _, price := range cf.prices {
+		prices = append(prices, price.String())
+	}
+	textEncoding := encodingForVersion(version, cf.seller, cf.description)
+	b, err := appendString([]byte{byte(textEncoding)}, strings.Join(prices, "/"), ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, commerceDate(cf.validUntil)...)
+	b, err = appendString(b, cf.contactURL, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, byte(cf.receivedAs))
+	b, err = appendString(b, cf.seller, textEncoding, true)
+	if err != nil {
+		return nil, err
+	}
+	b, err = appendString(b, cf.description, textEncoding, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(cf.logo) == 0 {
+		return b, nil
+	}
+	b, err = appendString(b, cf.logoMIME, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, cf.logo...), nil
+}
+
+// OwnershipFrame is an OWNE frame, recording the purchase of the file.
+type OwnershipFrame struct {
+	frameBase
+
+	price     Price
+	purchased time.Time
+	seller    string
+}
+
+func newOwnershipFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	of := &OwnershipFrame{}
+	of.header = header
+
+	l := len(data)
+	if l < 1 {
+		return nil, ErrTooShort
+	}
+	textEncoding, encoding, err := extractEncoding(l, data)
+	if err != nil {
+		return nil, err
+	}
+	price, i, err := readLatin1String(data[1:])
+	if err != nil {
+		return nil, err
+	}
+	i++
+	of.price = parsePrice(price)
+	if i+8 > l {
+		return nil, ErrTooShort
+	}
+	of.purchased = readCommerceDate(data[i : i+8])
+	i += 8
+	seller, _, err := trimForEncoding(l-i, data[i:], textEncoding, false)
+	if err != nil {
+		return nil, err
+	}
+	of.seller, err = decodeString(seller, encoding)
+	if err != nil {
+		return nil, err
+	}
+	return of, nil
+}
+
+func NewOwnershipFrame(id string, price Price, purchased time.Time, seller string) *OwnershipFrame {
+	of := &OwnershipFrame{}
+	of.header = newFrameHeader(id, 0, 0, 0)
+	of.price = price
+	of.purchased = purchased
+	of.seller = seller
+	return of
+}
+
+func (of *OwnershipFrame) Price() Price {
+	return of.price
+}
+
+// Purchased returns the date of purchase, or the zero time if the frame has
+// no valid date.
+func (of *OwnershipFrame) Purchased() time.Time {
+	return of.purchased
+}
+
+func (of *OwnershipFrame) Seller() string {
+	return of.seller
+}
+
+func (of *OwnershipFrame) String() string {
+	return fmt.Sprintf("%v from %v", of.price, of.seller)
+}
+
+func (of *OwnershipFrame) Bytes() []byte {
+	return []byte(of.String())
+}
+
+func (of *OwnershipFrame) encode(version uint8) ([]byte, error) {
+	textEncoding := encodingForVersion(version, of.seller)
+	b, err := appendString([]byte{byte(textEncoding)}, of.price.String(), ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, commerceDate(of.purchased)...)
+	return appendString(b, of.seller, textEncoding, false)
+}
+
+// TermsOfUseFrame is a USER frame, holding the terms of use of the file in a
+// given language.
+type TermsOfUseFrame struct {
+	frameBase
+
+	language language.Base
+	text     string
+}
+
+func newTermsOfUseFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	tf := &TermsOfUseFrame{}
+	tf.header = header
+
+	l := len(data)
+	if l < 4 {
+		return nil, ErrTooShort
+	}
+	textEncoding, encoding, err := extractEncoding(l, data)
+	if err != nil {
+		return nil, err
+	}
+	tf.language, err = readLanguage(data[1:4])
+	if err != nil {
+		return nil, err
+	}
+	text, _, err := trimForEncoding(l-4, data[4:], textEncoding, false)
+	if err != nil {
+		return nil, err
+	}
+	tf.text, err = decodeString(text, encoding)
+	if err != nil {
+		return nil, err
+	}
+	return tf, nil
+}
+
+func NewTermsOfUseFrame(id string, lang language.Base, text string) *TermsOfUseFrame {
+	tf := &TermsOfUseFrame{}
+	tf.header = newFrameHeader(id, 0, 0, uint32(len(text)))
+	tf.language = lang
+	tf.text = text
+	return tf
+}
+
+func (tf *TermsOfUseFrame) Language() language.Base {
+	return tf.language
+}
+
+func (tf *TermsOfUseFrame) String() string {
+	return tf.text
+}
+
+func (tf *TermsOfUseFrame) Bytes() []byte {
+	return []byte(tf.text)
+}
+
+func (tf *TermsOfUseFrame) encode(version uint8) ([]byte, error) {
+	textEncoding := encodingForVersion(version, tf.text)
+	b := []byte{byte(textEncoding)}
+	b = append(b, languageCode(tf.language)...)
+	return appendString(b, tf.text, textEncoding, false)
+}
+
+// Commercial returns the tag's COMR frames.
+func (tag *Tag) Commercial() []*CommercialFrame {
+	var frames []*CommercialFrame
+	for _, frame := range tag.framesOf(FrameCommercial) {
+		if cf, ok := frame.(*CommercialFrame); ok {
+			frames = append(frames, cf)
+		}
+	}
+	return frames
+}
+
+// Ownership returns the tag's OWNE frame, or nil.
+func (tag *Tag) Ownership() *OwnershipFrame {
+	of, _ := tag.Frame(FrameOwnership).(*OwnershipFrame)
+	return of
+}
+
+// TermsOfUse returns the terms of use in the given language, falling back to
+// the first USER frame if none match. It returns nil if there are none.
+func (tag *Tag) TermsOfUse(lang language.Base) *TermsOfUseFrame {
+	var first *TermsOfUseFrame
+	for _, frame := range tag.framesOf(FrameTermsOfUse) {
+		tf, ok := frame.(*TermsOfUseFrame)
+		if !ok {
+			continue
+		}
+		if tf.Language() == lang {
+			return tf
+		}
+		if first == nil {
+			first = tf
+		}
+	}
+	return first
+}
@@ -0,0 +1,241 @@
+package id3
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ConvertTo changes the tag to the given major version. Frame IDs are renamed,
+// v2.3 TYER/TDAT/TIME frames are merged into a v2.4 TDRC timestamp (and split
+// back again), and IPLS is mapped to TIPL while TIPL and TMCL are merged back
+// into IPLS. Frames that cannot be
+// represented in the target version are removed from the tag and returned.
+func (tag *Tag) ConvertTo(version uint8) ([]Frame, error) {
+	params, err := paramsForVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	from := tag.version()
+
+	var frames, dropped, dates, people []Frame
+	datePos, peoplePos := -1, -1
+	for _, frame := range tag.frames {
+		id := frame.Id()
+		kind := frameKindOf(id)
+		newId := kind.ID(version)
+		if newId == "" {
+			_, known := idsByKind[kind]
+			switch {
+			case isDateKind(kind):
+				if datePos < 0 {
+					datePos = len(frames)
+				}
+				dates = append(dates, frame)
+				continue
+			case isPeopleKind(kind):
+				if peoplePos < 0 {
+					peoplePos = len(frames)
+				}
+				people = append(people, frame)
+				continue
+			case !known && uint32(len(id)) == params.frameIdSize && params.frames[id] != nil:
+				newId = id
+			default:
+				dropped = append(dropped, frame)
+				continue
+			}
+		}
+		if _, err := frame.encode(version); err != nil {
+			dropped = append(dropped, frame)
+			continue
+		}
+		header := frame.base().header
+		header.id = newId
+		header.statusFlags = convertStatusFlags(header.statusFlags, from, version)
+		frames = append(frames, frame)
+	}
+
+	// Insert the merged frames where the frames they replace used to be,
+	// starting with the later position so the earlier one stays valid.
+	type insertion struct {
+		pos    int
+		frames []Frame
+	}
+	var insertions []insertion
+	if len(dates) > 0 {
+		present := make(map[FrameKind]bool)
+		for _, frame := range frames {
+			present[frameKindOf(frame.Id())] = true
+		}
+		converted, rest := convertDates(dates, version, present)
+		insertions = append(insertions, insertion{datePos, converted})
+		dropped = append(dropped, rest...)
+	}
+	if len(people) > 0 {
+		converted, rest := convertPeople(people, version)
+		insertions = append(insertions, insertion{peoplePos, converted})
+		dropped = append(dropped, rest...)
+	}
+	if len(insertions) == 2 && insertions[0].pos < insertions[1].pos {
+		insertions[0], insertions[1] = insertions[1], insertions[0]
+	}
+	for _, in := range insertions {
+		frames = append(frames[:in.pos], append(in.frames, frames[in.pos:]...)...)
+	}
+
+	if tag.Header == nil {
+		tag.Header = &Header{}
+	}
+	tag.Header.version = version
+	tag.Header.revision = 0
+	tag.setFrames(frames)
+	return dropped, nil
+}
+
+// copy returns a tag holding copies of the tag's frames, which ConvertTo can
+// change without changing the tag.
+func (tag *Tag) copy() *Tag {
+	var header *Header
+	if tag.Header != nil {
+		h := *tag.Header
+		header = &h
+	}
+	c := newTag(header, tag.ExtendedHeader)
+	c.offset = tag.offset
+	for _, frame := range tag.frames {
+		c.addFrame(copyFrame(frame))
+	}
+	return c
+}
+
+// copyFrame returns a shallow copy of a frame with its own header.
+func copyFrame(frame Frame) Frame {
+	v := reflect.ValueOf(frame).Elem()
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	copied := c.Interface().(Frame)
+	header := *frame.base().header
+	copied.base().header = &header
+	return copied
+}
+
+func (tag *Tag) setFrames(frames []Frame) {
+	tag.frames = nil
+	tag.frameMap = make(map[string][]Frame)
+	tag.titleFrame = nil
+	tag.artistFrame = nil
+	tag.albumFrame = nil
+	tag.yearFrame = nil
+	tag.genreFrame = nil
+	tag.commentFrames = nil
+	for _, frame := range frames {
+		tag.addFrame(frame)
+	}
+}
+
+func isDateKind(kind FrameKind) bool {
+	switch kind {
+	case FrameYear, FrameDate, FrameTime, FrameRecordingTime, FrameOriginalReleaseYear, FrameOriginalReleaseTime:
+		return true
+	}
+	return false
+}
+
+func isPeopleKind(kind FrameKind) bool {
+	switch kind {
+	case FrameInvolvedPeopleList, FrameInvolvedPeople, FrameMusicianCredits:
+		return true
+	}
+	return false
+}
+
+// convertDates turns v2.2/v2.3 year, date and time frames into v2.4 timestamps
+// or the reverse. Frames whose target is already present in the tag are
+// dropped rather than duplicated.
+func convertDates(dates []Frame, version uint8, present map[FrameKind]bool) ([]Frame, []Frame) {
+	values := make(map[FrameKind]string)
+	for _, frame := range dates {
+		values[frameKindOf(frame.Id())] = strings.TrimSpace(frame.String())
+	}
+	var converted, dropped []Frame
+	add := func(kind FrameKind, value string) {
+		converted = append(converted, NewTextFrame(kind.ID(version), value))
+		present[kind] = true
+	}
+	for _, frame := range dates {
+		kind := frameKindOf(frame.Id())
+		switch {
+		case kind == FrameYear && present[FrameRecordingTime],
+			kind == FrameRecordingTime && present[FrameYear],
+			kind == FrameOriginalReleaseYear && present[FrameOriginalReleaseTime],
+			kind == FrameOriginalReleaseTime && present[FrameOriginalReleaseYear]:
+			dropped = append(dropped, frame)
+			continue
+		}
+		switch kind {
+		case FrameYear:
+			if len(values[FrameYear]) < 4 {
+				dropped = append(dropped, frame)
+				continue
+			}
+			timestamp := values[FrameYear][:4]
+			if date := values[FrameDate]; len(date) == 4 {
+				timestamp += "-" + date[2:4] + "-" + date[0:2]
+				if t := values[FrameTime]; len(t) == 4 {
+					timestamp += "T" + t[0:2] + ":" + t[2:4]
+				}
+			}
+			add(FrameRecordingTime, timestamp)
+		case FrameRecordingTime:
+			timestamp := values[FrameRecordingTime]
+			if len(timestamp) < 4 {
+				dropped = append(dropped, frame)
+				continue
+			}
+			add(FrameYear, timestamp[:4])
+			if len(timestamp) >= 10 {
+				add(FrameDate, timestamp[8:10]+timestamp[5:7])
+			}
+			if len(timestamp) >= 16 {
+				add(FrameTime, timestamp[11:13]+timestamp[14:16])
+			}
+		case FrameOriginalReleaseYear:
+			add(FrameOriginalReleaseTime, values[kind])
+		case FrameOriginalReleaseTime:
+			if len(values[kind]) < 4 {
+				dropped = append(dropped, frame)
+				continue
+			}
+			add(FrameOriginalReleaseYear, values[kind][:4])
+		case FrameDate, FrameTime:
+			// Merged into the timestamp along with the year
+			if values[FrameYear] == "" {
+				dropped = append(dropped, frame)
+			}
+		}
+	}
+	return converted, dropped
+}
+
+// convertPeople maps a v2.2/v2.3 involved people list to a v2.4 TIPL frame, or
+// merges v2.4 TIPL and TMCL frames into a single involved people list.
+func convertPeople(people []Frame, version uint8) ([]Frame, []Frame) {
+	var credits []InvolvedPerson
+	var dropped []Frame
+	for _, frame := range people {
+		ipf, ok := frame.(*InvolvedPeopleFrame)
+		if !ok {
+			dropped = append(dropped, frame)
+			continue
+		}
+		credits = append(credits, ipf.people...)
+	}
+	if len(credits) == 0 {
+		return nil, dropped
+	}
+	id := FrameInvolvedPeopleList.ID(version)
+	if version >= 4 {
+		id = FrameInvolvedPeople.ID(version)
+	}
+	return []Frame{NewInvolvedPeopleFrame(id, credits)}, dropped
+}
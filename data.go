@@ -24,3 +24,7 @@ func (df *DataFrame) String() string {
 func (df *DataFrame) Bytes() []byte {
 	return []byte(df.value)
 }
+
+func (df *DataFrame) encode(version uint8) ([]byte, error) {
+	return df.value, nil
+}
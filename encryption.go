@@ -0,0 +1,330 @@
+package id3
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// AudioEncryptionFrame is an AENC frame, or CRA in v2.2, registering the
+// owner of encrypted audio and the part of it that may be played unencrypted
+// as a preview.
+type AudioEncryptionFrame struct {
+	frameBase
+
+	owner         string
+	previewStart  uint16
+	previewLength uint16
+	info          []byte
+}
+
+func newAudioEncryptionFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	aef := &AudioEncryptionFrame{}
+	aef.header = header
+
+	owner, i, err := readLatin1String(data)
+	if err != nil {
+		return nil, err
+	}
+	if i+4 > len(data) {
+		return nil, ErrTooShort
+	}
+	aef.owner = owner
+	aef.previewStart = binary.BigEndian.Uint16(data[i:])
+	aef.previewLength = binary.BigEndian.Uint16(data[i+2:])
+	aef.info = data[i+4:]
+	return aef, nil
+}
+
+func NewAudioEncryptionFrame(id string, owner string, previewStart uint16, previewLength uint16, info []byte) *AudioEncryptionFrame {
+	aef := &AudioEncryptionFrame{}
+	aef.header = newFrameHeader(id, 0, 0, 0)
+	aef.owner = owner
+	aef.previewStart = previewStart
+	aef.previewLength = previewLength
+	aef.info = info
+	return aef
+}
+
+func (aef *AudioEncryptionFrame) Owner() string {
+	return aef.owner
+}
+
+// Preview returns the first unencrypted frame of the audio and the number of
+// unencrypted frames from there on, both in MPEG frames.
+func (aef *AudioEncryptionFrame) Preview() (uint16, uint16) {
+	return aef.previewStart, aef.previewLength
+}
+
+func (aef *AudioEncryptionFrame) EncryptionInfo() []byte {
+	return aef.info
+}
+
+func (aef *AudioEncryptionFrame) String() string {
+	return fmt.Sprintf("%v (preview %v+%v)", aef.owner, aef.previewStart, aef.previewLength)
+}
+
+func (aef *AudioEncryptionFrame) Bytes() []byte {
+	return aef.info
+}
+
+func (aef *AudioEncryptionFrame) encode(version uint8) ([]byte, error) {
+	b, err := appendString(nil, aef.owner, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, 0, 0, 0, 0)
+	binary.BigEndian.PutUint16(b[len(b)-4:], aef.previewStart)
+	binary.BigEndian.PutUint16(b[len(b)-2:], aef.previewLength)
+	return append(b, aef.info...), nil
+}
+
+// RegistrationFrame is an ENCR or GRID frame, registering the symbol that
+// frames of the tag use to name their encryption method or group.
+type RegistrationFrame struct {
+	frameBase
+
+	owner  string
+	symbol byte
+	data   []byte
+}
+
+func newRegistrationFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	rf := &RegistrationFrame{}
+	rf.header = header
+
+	owner, i, err := readLatin1String(data)
+	if err != nil {
+		return nil, err
+	}
+	if i >= len(data) {
+		return nil, ErrTooShort
+	}
+	rf.owner = owner
+	rf.symbol = data[i]
+	rf.data = data[i+1:]
+	return rf, nil
+}
+
+// NewRegistrationFrame creates an ENCR or GRID frame. Symbols below 0x80 are
+// reserved.
+func NewRegistrationFrame(id string, owner string, symbol byte, data []byte) *RegistrationFrame {
+	rf := &RegistrationFrame{}
+	rf.header = newFrameHeader(id, 0, 0, uint32(len(data)))
+	rf.owner = owner
+	rf.symbol = symbol
+	rf.data = data
+	return rf
+}
+
+func (rf *RegistrationFrame) Owner() string {
+	return rf.owner
+}
+
+func (rf *RegistrationFrame) Symbol() byte {
+	return rf.symbol
+}
+
+// Data returns the encryption data of an ENCR frame, or the group dependent
+// data of a GRID frame.
+func (rf *RegistrationFrame) Data() []byte {
+	return rf.data
+}
+
+func (rf *RegistrationFrame) String() string {
+	return fmt.Sprintf("%#02x: %v", rf.symbol, rf.owner)
+}
+
+func (rf *RegistrationFrame) Bytes() []byte {
+	return rf.data
+}
+
+func (rf *RegistrationFrame) encode(version uint8) ([]byte, error) {
+	b, err := appendString(nil, rf.owner, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, rf.symbol)
+	return append(b, rf.data...), nil
+}
+
+// EncryptedMetaFrame is a v2.2 CRM frame, wrapping one or more encrypted
+// frames along with an explanation of their content.
+type EncryptedMetaFrame struct {
+	frameBase
+
+	owner       string
+	explanation string
+	data        []byte
+}
+
+func newEncryptedMetaFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	emf := &EncryptedMetaFrame{}
+	emf.header = header
+
+	owner, i, err := readLatin1String(data)
+	if err != nil {
+		return nil, err
+	}
+	explanation, j, err := readLatin1String(data[i:])
+	if err != nil {
+		return nil, err
+	}
+	emf.owner = owner
+	emf.explanation = explanation
+	emf.data = data[i+j:]
+	return emf, nil
+}
+
+func (emf *EncryptedMetaFrame) Owner() string {
+	return emf.owner
+}
+
+func (emf *EncryptedMetaFrame) Explanation() string {
+	return emf.explanation
+}
+
+func (emf *EncryptedMetaFrame) String() string {
+	return fmt.Sprintf("%v (%v)", emf.explanation, emf.owner)
+}
+
+func (emf *EncryptedMetaFrame) Bytes() []byte {
+	return emf.data
+}
+
+func (emf *EncryptedMetaFrame) encode(version uint8) ([]byte, error) {
+	b, err := appendString(nil, emf.owner, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	b, err = appendString(b, emf.explanation, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, emf.data...), nil
+}
+
+// EncryptedFrame is a frame whose format flags mark it as encrypted. Its
+// data is kept as read, and can be decoded with Tag.Decrypt.
+type EncryptedFrame struct {
+	frameBase
+
+	data []byte
+}
+
+func newEncryptedFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	ef := &EncryptedFrame{}
+	ef.header = header
+	ef.data = data
+	return ef, nil
+}
+
+func (ef *EncryptedFrame) String() string {
+	return hex.EncodeToString(ef.data)
+}
+
+func (ef *EncryptedFrame) Bytes() []byte {
+	return ef.data
+}
+
+func (ef *EncryptedFrame) encode(version uint8) ([]byte, error) {
+	return ef.data, nil
+}
+
+// Decrypter decrypts the data of encrypted frames. It is given the ENCR
+// frame registering the frame's encryption method, or nil if the tag has
+// none.
+type Decrypter interface {
+	Decrypt(method byte, registration *RegistrationFrame, data []byte) ([]byte, error)
+}
+
+// DecrypterFunc adapts a function to a Decrypter.
+type DecrypterFunc func(method byte, registration *RegistrationFrame, data []byte) ([]byte, error)
+
+func (f DecrypterFunc) Decrypt(method byte, registration *RegistrationFrame, data []byte) ([]byte, error) {
+	return f(method, registration, data)
+}
+
+// Decrypt replaces the tag's encrypted frames with the frames the decrypter
+// decodes them to. Decrypted frames are written unencrypted. Frames that
+// fail to decrypt are left as they are, and the first error is returned.
+func (tag *Tag) Decrypt(d Decrypter) error {
+	params, err := paramsForVersion(tag.version())
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	frames := tag.frames
+	for i, frame := range frames {
+		ef, ok := frame.(*EncryptedFrame)
+		if !ok {
+			continue
+		}
+		decrypted, err := tag.decryptFrame(ef, params, d)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = errors.New(fmt.Sprintf("Error decrypting frame %v: %v", ef.Id(), err))
+			}
+			continue
+		}
+		frames[i] = decrypted
+	}
+
+	tag.setFrames(frames)
+	return firstErr
+}
+
+func (tag *Tag) decryptFrame(ef *EncryptedFrame, params *versionParams, d Decrypter) (Frame, error) {
+	method, _ := ef.EncryptionMethod()
+	data, err := d.Decrypt(method, tag.EncryptionMethod(method), ef.data)
+	if err != nil {
+		return nil, err
+	}
+	header := *ef.header
+	header.encrypted = false
+	header.encryptionMethod = 0
+	if header.compressed {
+		data, err = decompress(data, header.dataLength)
+		if err != nil {
+			return nil, err
+		}
+	}
+	factory, ok := params.frames[header.id]
+	if !ok {
+		return newDataFrame(tag, &header, data)
+	}
+	return factory.maker(tag, &header, data)
+}
+
+// EncryptionMethod returns the ENCR frame registering the given method
+// symbol, or nil.
+func (tag *Tag) EncryptionMethod(method byte) *RegistrationFrame {
+	return tag.registration(FrameEncryptionMethodRegistration, method)
+}
+
+// GroupRegistration returns the GRID frame registering the given group
+// symbol, or nil.
+func (tag *Tag) GroupRegistration(group byte) *RegistrationFrame {
+	return tag.registration(FrameGroupIdentificationRegistration, group)
+}
+
+func (tag *Tag) registration(kind FrameKind, symbol byte) *RegistrationFrame {
+	for _, frame := range tag.framesOf(kind) {
+		if rf, ok := frame.(*RegistrationFrame); ok && rf.Symbol() == symbol {
+			return rf
+		}
+	}
+	return nil
+}
+
+// GroupFrames returns the frames belonging to the given group.
+func (tag *Tag) GroupFrames(group byte) []Frame {
+	var frames []Frame
+	for _, frame := range tag.frames {
+		if id, ok := frame.GroupID(); ok && id == group {
+			frames = append(frames, frame)
+		}
+	}
+	return frames
+}
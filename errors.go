@@ -5,3 +5,13 @@ import "errors"
 var ErrTooShort = errors.New("invalid file; too short")
 var ErrNoHeader = errors.New("invalid file; missing ID3 Header")
 var ErrCorruptExtendedHeader = errors.New("invalid file; Extended Header is too short")
+var ErrTooLarge = errors.New("invalid tag; too large to write")
+var ErrReadOnly = errors.New("file was opened read-only")
+var ErrNegativeCounter = errors.New("invalid frame; counter is negative")
+var ErrTimestampFormat = errors.New("frame timestamps are not in milliseconds")
+var ErrTooManyChapters = errors.New("invalid chapters; a table of contents holds at most 255")
+var ErrChapterOrder = errors.New("invalid chapters; chapters must be in order and end after they start")
+var ErrFFMetadata = errors.New("invalid FFmpeg metadata file")
+var ErrVolumeWidth = errors.New("invalid frame; volume fields are wider than 64 bits")
+var ErrDecompressedSize = errors.New("invalid frame; decompressed size does not match the frame")
+var ErrInvalidEvent = errors.New("invalid event; EventMore only extends the event type")
@@ -1,15 +1,100 @@
 package id3
 
-import "io"
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
 
 type ExtendedHeader struct {
 	size uint32
-	data []byte
+
+	update       bool
+	hasCRC       bool
+	crc          uint32
+	crcValid     bool
+	paddingSize  uint32
+	restrictions *Restrictions
+}
+
+type TagSizeRestriction byte
+
+const (
+	TagSizeMax128Frames1MB TagSizeRestriction = iota
+	TagSizeMax64Frames128KB
+	TagSizeMax32Frames40KB
+	TagSizeMax32Frames4KB
+)
+
+type TextEncodingRestriction byte
+
+const (
+	TextEncodingUnrestricted TextEncodingRestriction = iota
+	TextEncodingISO88591OrUTF8
+)
+
+type TextFieldSizeRestriction byte
+
+const (
+	TextFieldSizeUnrestricted TextFieldSizeRestriction = iota
+	TextFieldSizeMax1024
+	TextFieldSizeMax128
+	TextFieldSizeMax30
+)
+
+type ImageEncodingRestriction byte
+
+const (
+	ImageEncodingUnrestricted ImageEncodingRestriction = iota
+	ImageEncodingPNGOrJPEG
+)
+
+type ImageSizeRestriction byte
+
+const (
+	ImageSizeUnrestricted ImageSizeRestriction = iota
+	ImageSizeMax256
+	ImageSizeMax64
+	ImageSizeExactly64
+)
+
+// Restrictions are the v2.4 tag restrictions an encoder promised to respect.
+type Restrictions struct {
+	TagSize       TagSizeRestriction
+	TextEncoding  TextEncodingRestriction
+	TextFieldSize TextFieldSizeRestriction
+	ImageEncoding ImageEncodingRestriction
+	ImageSize     ImageSizeRestriction
 }
 
-const extendedHeaderSizeSize uint = 4
+func newRestrictions(b byte) *Restrictions {
+	return &Restrictions{
+		TagSize:       TagSizeRestriction(b >> 6),
+		TextEncoding:  TextEncodingRestriction((b >> 5) & 0x01),
+		TextFieldSize: TextFieldSizeRestriction((b >> 3) & 0x03),
+		ImageEncoding: ImageEncodingRestriction((b >> 2) & 0x01),
+		ImageSize:     ImageSizeRestriction(b & 0x03),
+	}
+}
+
+const (
+	extendedHeaderSizeSize uint = 4
+
+	extendedFlagCRCV23 uint16 = 0x8000
 
-func newExtendedHeader(r io.ReadSeeker) (*ExtendedHeader, error) {
+	extendedFlagUpdateV24       byte = 0x40
+	extendedFlagCRCV24          byte = 0x20
+	extendedFlagRestrictionsV24 byte = 0x10
+)
+
+// newExtendedHeader reads the extended header at the start of a tag of the
+// given size, which the extended header must fit in.
+func newExtendedHeader(r io.ReadSeeker, version uint8, tagSize uint32) (*ExtendedHeader, error) {
+	if tagSize < uint32(extendedHeaderSizeSize) {
+		return nil, ErrCorruptExtendedHeader
+	}
 	s := make([]byte, extendedHeaderSizeSize)
 	n, err := io.ReadFull(r, s)
 	if err != nil {
@@ -18,21 +103,144 @@ func newExtendedHeader(r io.ReadSeeker) (*ExtendedHeader, error) {
 	if uint(n) < extendedHeaderSizeSize {
 		return nil, ErrCorruptExtendedHeader
 	}
-	size := unsafe(s)
-	if size < 6 {
-		return nil, ErrCorruptExtendedHeader
+	var size uint32
+	switch version {
+	case 3:
+		// The size excludes the size field itself
+		size = binary.BigEndian.Uint32(s)
+		if size < 6 {
+			return nil, ErrCorruptExtendedHeader
+		}
+	case 4:
+		size = unsafe(s)
+		if size < 6 {
+			return nil, ErrCorruptExtendedHeader
+		}
+		size -= uint32(extendedHeaderSizeSize)
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown extended header revision: %v", version))
 	}
-	e := &ExtendedHeader{
-		size: size,
-		data: make([]byte, size),
+	if size > tagSize-uint32(extendedHeaderSizeSize) {
+		return nil, ErrCorruptExtendedHeader
 	}
-	n, err = io.ReadFull(r, e.data)
+	data := make([]byte, size)
+	n, err = io.ReadFull(r, data)
 	if err != nil {
 		return nil, err
 	}
 	if uint32(n) != size {
 		return nil, ErrCorruptExtendedHeader
 	}
+	e := &ExtendedHeader{
+		size:     size + uint32(extendedHeaderSizeSize),
+		crcValid: true,
+	}
+	if version == 3 {
+		err = e.read23(data)
+	} else {
+		err = e.read24(data)
+	}
+	if err != nil {
+		return nil, err
+	}
 	return e, nil
+}
+
+func (e *ExtendedHeader) read23(data []byte) error {
+	flags := binary.BigEndian.Uint16(data)
+	e.paddingSize = binary.BigEndian.Uint32(data[2:])
+	if flags&extendedFlagCRCV23 != 0 {
+		if len(data) < 10 {
+			return ErrCorruptExtendedHeader
+		}
+		e.hasCRC = true
+		e.crc = binary.BigEndian.Uint32(data[6:])
+	}
+	return nil
+}
+
+func (e *ExtendedHeader) read24(data []byte) error {
+	flagBytes := int(data[0])
+	if flagBytes < 1 || len(data) < 1+flagBytes {
+		return ErrCorruptExtendedHeader
+	}
+	flags := data[1]
+	data = data[1+flagBytes:]
+
+	// Each flag that is set is followed by its data, in flag order
+	next := func() ([]byte, error) {
+		if len(data) < 1 || len(data) < 1+int(data[0]) {
+			return nil, ErrCorruptExtendedHeader
+		}
+		d := data[1 : 1+int(data[0])]
+		data = data[1+int(data[0]):]
+		return d, nil
+	}
+	if flags&extendedFlagUpdateV24 != 0 {
+		_, err := next()
+		if err != nil {
+			return err
+		}
+		e.update = true
+	}
+	if flags&extendedFlagCRCV24 != 0 {
+		d, err := next()
+		if err != nil {
+			return err
+		}
+		if len(d) != 5 {
+			return ErrCorruptExtendedHeader
+		}
+		e.hasCRC = true
+		for _, b := range d {
+			e.crc = e.crc<<7 | uint32(b&0x7F)
+		}
+	}
+	if flags&extendedFlagRestrictionsV24 != 0 {
+		d, err := next()
+		if err != nil {
+			return err
+		}
+		if len(d) != 1 {
+			return ErrCorruptExtendedHeader
+		}
+		e.restrictions = newRestrictions(d[0])
+	}
+	return nil
+}
+
+// verifyCRC checks the CRC-32 against the tag data following the extended
+// header. v2.3 excludes the padding from the CRC, v2.4 includes it.
+func (e *ExtendedHeader) verifyCRC(version uint8, data []byte) bool {
+	if version == 3 && e.paddingSize <= uint32(len(data)) {
+		data = data[:uint32(len(data))-e.paddingSize]
+	}
+	e.crcValid = crc32.ChecksumIEEE(data) == e.crc
+	return e.crcValid
+}
+
+// IsUpdate reports whether the tag updates an earlier tag in the stream.
+func (e *ExtendedHeader) IsUpdate() bool {
+	return e.update
+}
+
+// CRC returns the CRC-32 of the tag data, and whether the tag has one at all.
+func (e *ExtendedHeader) CRC() (uint32, bool) {
+	return e.crc, e.hasCRC
+}
+
+// CRCValid reports whether the CRC matched the frame data when the tag was
+// read. Tags without a CRC are always valid.
+func (e *ExtendedHeader) CRCValid() bool {
+	return e.crcValid
+}
+
+// PaddingSize returns the padding size recorded in a v2.3 extended header.
+func (e *ExtendedHeader) PaddingSize() uint32 {
+	return e.paddingSize
+}
 
+// Restrictions returns the v2.4 tag restrictions, or nil if there are none.
+func (e *ExtendedHeader) Restrictions() *Restrictions {
+	return e.restrictions
 }
@@ -0,0 +1,229 @@
+package id3
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// File is an ID3 tagged file opened for reading and updating. Changes made to
+// the Tag are written back by Save or Close.
+type File struct {
+	*Tag
+
+	path         string
+	f            *os.File
+	readOnly     bool
+	originalSize uint32
+	snapshot     []byte
+	// appendedStart and appendedEnd locate a tag appended to the end of the
+	// file, which is removed when the tag is written in front
+	appendedStart int64
+	appendedEnd   int64
+}
+
+func Open(path string) (*File, error) {
+	var readOnly bool
+	f, err := os.OpenFile(path, os.O_RDWR, 0666)
+	if os.IsPermission(err) {
+		readOnly = true
+		f, err = os.OpenFile(path, os.O_RDONLY, 0666)
+	}
+	if err != nil {
+		return nil, err
+	}
+	file := &File{
+		path:     path,
+		f:        f,
+		readOnly: readOnly,
+	}
+	err = file.parse()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func (file *File) parse() error {
+	_, err := file.f.Seek(0, os.SEEK_SET)
+	if err != nil {
+		return err
+	}
+	tag, err := Read(file.f)
+	switch {
+	case err == nil && tag.Header != nil && tag.offset == 0:
+		file.originalSize = tag.Header.tagSize()
+	case err == nil && tag.Header != nil:
+		// Only a tag appended to the end; a new one is written in front
+		file.originalSize = 0
+		file.appendedStart = tag.offset
+		file.appendedEnd = tag.offset + int64(tag.Header.tagSize())
+	case err == nil:
+		// Only an ID3v1 tag; its values start the tag written in front
+		_, err = tag.ConvertTo(4)
+		if err != nil {
+			return err
+		}
+		file.originalSize = 0
+	case err == ErrNoHeader, err == ErrTooShort, err == io.EOF, err == io.ErrUnexpectedEOF:
+		// No ID3 tag; start a fresh one in front of the audio
+		tag = newTag(&Header{version: 4}, nil)
+		file.originalSize = 0
+	default:
+		return err
+	}
+	file.Tag = tag
+	file.snapshot = file.Tag.snapshot()
+	return nil
+}
+
+// Save writes the tag back to the file if it has been modified. The tag is
+// rewritten in place when it fits in the space used by the existing tag,
+// otherwise the file is rewritten with the audio copied after the new tag.
+func (file *File) Save() error {
+	if bytes.Equal(file.snapshot, file.Tag.snapshot()) {
+		return nil
+	}
+	if file.readOnly {
+		return ErrReadOnly
+	}
+	// Any tags a SEEK frame pointed to have already been merged into this one
+	file.Tag.RemoveFrames(FrameSeek)
+	version := file.Tag.version()
+	params, err := paramsForVersion(version)
+	if err != nil {
+		return err
+	}
+	b, err := file.Tag.writeV2(version, 0, params)
+	if err != nil {
+		return err
+	}
+	size := uint32(len(b))
+	if size <= file.originalSize {
+		b, err = file.Tag.writeV2(version, file.originalSize-size, params)
+		if err != nil {
+			return err
+		}
+		_, err = file.f.WriteAt(b, 0)
+		if err != nil {
+			return err
+		}
+		err = file.f.Sync()
+	} else {
+		b, err = file.Tag.writeV2(version, defaultPaddingSize, params)
+		if err != nil {
+			return err
+		}
+		err = file.rewrite(b)
+	}
+	if err != nil {
+		return err
+	}
+	file.Tag.Header = &Header{
+		version:     version,
+		frameSize:   uint32(len(b)) - headerSize,
+		paddingSize: uint32(len(b)) - size,
+	}
+	file.Tag.ExtendedHeader = nil
+	file.originalSize = uint32(len(b))
+	file.appendedStart, file.appendedEnd = 0, 0
+	file.snapshot = file.Tag.snapshot()
+	return nil
+}
+
+func (file *File) rewrite(tag []byte) error {
+	info, err := file.f.Stat()
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file.path), "."+filepath.Base(file.path))
+	if err != nil {
+		return err
+	}
+	err = file.copyWithTag(tmp, tag)
+	if err == nil {
+		err = tmp.Chmod(info.Mode())
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	file.f.Close()
+	err = os.Rename(tmp.Name(), file.path)
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	f, oerr := os.OpenFile(file.path, os.O_RDWR, 0666)
+	if oerr != nil {
+		return oerr
+	}
+	file.f = f
+	return err
+}
+
+func (file *File) copyWithTag(w io.Writer, tag []byte) error {
+	_, err := w.Write(tag)
+	if err != nil {
+		return err
+	}
+	_, err = file.f.Seek(int64(file.originalSize), os.SEEK_SET)
+	if err != nil {
+		return err
+	}
+	if file.appendedEnd > file.appendedStart {
+		_, err = io.CopyN(w, file.f, file.appendedStart-int64(file.originalSize))
+		if err != nil {
+			return err
+		}
+		_, err = file.f.Seek(file.appendedEnd, os.SEEK_SET)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = io.Copy(w, file.f)
+	return err
+}
+
+// Close saves any changes to the tag and closes the underlying file.
+func (file *File) Close() error {
+	err := file.Save()
+	if cerr := file.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// snapshot captures the frames of the tag so that Save can tell whether
+// anything has changed since the file was read.
+func (tag *Tag) snapshot() []byte {
+	var b bytes.Buffer
+	version := tag.version()
+	for _, frame := range tag.frames {
+		b.WriteString(frame.Id())
+		var flags byte
+		groupId, grouped := frame.GroupID()
+		if grouped {
+			flags |= 0x01
+		}
+		if frame.Compressed() {
+			flags |= 0x02
+		}
+		b.Write([]byte{flags, groupId})
+		data, err := frame.encode(version)
+		if err != nil {
+			b.WriteString(err.Error())
+			continue
+		}
+		b.Write(data)
+	}
+	return b.Bytes()
+}
@@ -1,5 +1,15 @@
 package id3
 
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
 type Frame interface {
 	Id() string
 	Size() uint32
@@ -7,8 +17,26 @@ type Frame interface {
 	FormatFlags() byte
 	String() string
 	Bytes() []byte
+	GroupID() (byte, bool)
+	EncryptionMethod() (byte, bool)
+	Compressed() bool
+
+	base() *frameBase
+	encode(version uint8) ([]byte, error)
 }
 
+const (
+	formatCompressedV23 byte = 0x80
+	formatEncryptedV23  byte = 0x40
+	formatGroupedV23    byte = 0x20
+
+	formatGroupedV24        byte = 0x40
+	formatCompressedV24     byte = 0x08
+	formatEncryptedV24      byte = 0x04
+	formatUnsynchronizedV24 byte = 0x02
+	formatDataLengthV24     byte = 0x01
+)
+
 type frameBase struct {
 	header *frameHeader
 }
@@ -29,11 +57,56 @@ func (fb *frameBase) Size() uint32 {
 	return fb.header.size
 }
 
+func (fb *frameBase) base() *frameBase {
+	return fb
+}
+
+// GroupID returns the group identity byte of the frame, and whether the frame
+// belongs to a group at all.
+func (fb *frameBase) GroupID() (byte, bool) {
+	return fb.header.groupId, fb.header.grouped
+}
+
+// SetGroupID makes the frame part of the group with the given identity when the
+// tag is written.
+func (fb *frameBase) SetGroupID(id byte) {
+	fb.header.grouped = true
+	fb.header.groupId = id
+}
+
+func (fb *frameBase) ClearGroupID() {
+	fb.header.grouped = false
+	fb.header.groupId = 0
+}
+
+// EncryptionMethod returns the encryption method symbol of the frame, and
+// whether the frame is encrypted at all.
+func (fb *frameBase) EncryptionMethod() (byte, bool) {
+	return fb.header.encryptionMethod, fb.header.encrypted
+}
+
+func (fb *frameBase) Compressed() bool {
+	return fb.header.compressed
+}
+
+// SetCompressed controls whether the frame is zlib compressed when the tag is
+// written as v2.3 or v2.4.
+func (fb *frameBase) SetCompressed(compressed bool) {
+	fb.header.compressed = compressed
+}
+
 type frameHeader struct {
 	id          string
 	statusFlags byte
 	formatFlags byte
 	size        uint32
+
+	compressed       bool
+	encrypted        bool
+	encryptionMethod byte
+	grouped          bool
+	groupId          byte
+	dataLength       uint32
 }
 
 func newFrameHeader(id string, statusFlags byte, formatFlags byte, size uint32) *frameHeader {
@@ -44,3 +117,205 @@ func newFrameHeader(id string, statusFlags byte, formatFlags byte, size uint32)
 		size:        size,
 	}
 }
+
+// convertStatusFlags moves the tag alter, file alter and read only bits
+// between the v2.3 (%abc00000) and v2.4 (%0abc0000) layouts.
+func convertStatusFlags(flags byte, from uint8, to uint8) byte {
+	switch {
+	case from <= 2 || to <= 2:
+		return 0
+	case from == 3 && to == 4:
+		return (flags >> 1) & 0x70
+	case from == 4 && to == 3:
+		return (flags << 1) & 0xE0
+	}
+	return flags
+}
+
+// readFormat interprets the format flags of a frame read from a tag of the
+// given version, stripping the extra header bytes they add and returning the
+// frame data ready for its factory.
+func (fh *frameHeader) readFormat(version uint8, unsynchronized bool, data []byte) ([]byte, error) {
+	switch version {
+	case 3:
+		fh.compressed = fh.formatFlags&formatCompressedV23 != 0
+		fh.encrypted = fh.formatFlags&formatEncryptedV23 != 0
+		fh.grouped = fh.formatFlags&formatGroupedV23 != 0
+		if fh.compressed {
+			if len(data) < 4 {
+				return nil, ErrTooShort
+			}
+			fh.dataLength = binary.BigEndian.Uint32(data)
+			data = data[4:]
+		}
+		if fh.encrypted {
+			if len(data) < 1 {
+				return nil, ErrTooShort
+			}
+			fh.encryptionMethod = data[0]
+			data = data[1:]
+		}
+		if fh.grouped {
+			if len(data) < 1 {
+				return nil, ErrTooShort
+			}
+			fh.groupId = data[0]
+			data = data[1:]
+		}
+	case 4:
+		fh.grouped = fh.formatFlags&formatGroupedV24 != 0
+		fh.compressed = fh.formatFlags&formatCompressedV24 != 0
+		fh.encrypted = fh.formatFlags&formatEncryptedV24 != 0
+		if fh.grouped {
+			if len(data) < 1 {
+				return nil, ErrTooShort
+			}
+			fh.groupId = data[0]
+			data = data[1:]
+		}
+		if fh.encrypted {
+			if len(data) < 1 {
+				return nil, ErrTooShort
+			}
+			fh.encryptionMethod = data[0]
+			data = data[1:]
+		}
+		if fh.formatFlags&formatDataLengthV24 != 0 {
+			if len(data) < 4 {
+				return nil, ErrTooShort
+			}
+			fh.dataLength = unsafe(data)
+			data = data[4:]
+		}
+		if unsynchronized || fh.formatFlags&formatUnsynchronizedV24 != 0 {
+			data = resync(data)
+		}
+	}
+	if fh.compressed && !fh.encrypted {
+		return decompress(data, fh.dataLength)
+	}
+	return data, nil
+}
+
+// writeFormat returns the format flags for a frame written to a tag of the
+// given version, along with the frame data including any extra header bytes.
+func (fh *frameHeader) writeFormat(version uint8, data []byte) (byte, []byte, error) {
+	if fh.encrypted {
+		return fh.writeEncryptedFormat(version, data)
+	}
+	var flags byte
+	var extra []byte
+	switch version {
+	case 3:
+		if fh.compressed {
+			extra = make([]byte, 4)
+			binary.BigEndian.PutUint32(extra, uint32(len(data)))
+			compressed, err := compress(data)
+			if err != nil {
+				return 0, nil, err
+			}
+			data = compressed
+			flags |= formatCompressedV23
+		}
+		if fh.grouped {
+			extra = append(extra, fh.groupId)
+			flags |= formatGroupedV23
+		}
+	case 4:
+		if fh.grouped {
+			extra = append(extra, fh.groupId)
+			flags |= formatGroupedV24
+		}
+		if fh.compressed {
+			extra = append(extra, safe(uint32(len(data)))...)
+			compressed, err := compress(data)
+			if err != nil {
+				return 0, nil, err
+			}
+			data = compressed
+			flags |= formatCompressedV24 | formatDataLengthV24
+		}
+	}
+	if len(extra) == 0 {
+		return flags, data, nil
+	}
+	return flags, append(extra, data...), nil
+}
+
+// writeEncryptedFormat is writeFormat for frames still encrypted as read, whose
+// data was compressed, if at all, before it was encrypted.
+func (fh *frameHeader) writeEncryptedFormat(version uint8, data []byte) (byte, []byte, error) {
+	var flags byte
+	var extra []byte
+	switch version {
+	case 3:
+		if fh.compressed {
+			extra = make([]byte, 4)
+			binary.BigEndian.PutUint32(extra, fh.dataLength)
+			flags |= formatCompressedV23
+		}
+		extra = append(extra, fh.encryptionMethod)
+		flags |= formatEncryptedV23
+		if fh.grouped {
+			extra = append(extra, fh.groupId)
+			flags |= formatGroupedV23
+		}
+	case 4:
+		if fh.grouped {
+			extra = append(extra, fh.groupId)
+			flags |= formatGroupedV24
+		}
+		extra = append(extra, fh.encryptionMethod)
+		flags |= formatEncryptedV24
+		if fh.compressed {
+			flags |= formatCompressedV24
+		}
+		if fh.compressed || fh.dataLength > 0 {
+			extra = append(extra, safe(fh.dataLength)...)
+			flags |= formatDataLengthV24
+		}
+	default:
+		return 0, nil, errors.New(fmt.Sprintf("Encrypted frame %v cannot be written to a v2.%v tag", fh.id, version))
+	}
+	return flags, append(extra, data...), nil
+}
+
+func compress(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	_, err := w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// maxDecompressedSize caps frames without a declared decompressed size, at
+// the largest size a v2.4 tag can hold.
+const maxDecompressedSize uint32 = 1 << 28
+
+// decompress inflates frame data to the declared size, or to at most
+// maxDecompressedSize if the size is 0.
+func decompress(data []byte, size uint32) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	limit := size
+	if limit == 0 {
+		limit = maxDecompressedSize
+	}
+	out, err := ioutil.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(out)) > limit || (size > 0 && uint32(len(out)) != size) {
+		return nil, ErrDecompressedSize
+	}
+	return out, nil
+}
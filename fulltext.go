@@ -13,6 +13,7 @@ type FullTextFrame struct {
 	language    language.Base
 	description string
 	text        string
+	described   bool
 }
 
 func newFullTextFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
@@ -20,19 +21,16 @@ func newFullTextFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error)
 	ftf.header = header
 
 	l := len(data)
+	if l < 4 {
+		return nil, ErrTooShort
+	}
 	textEncoding, encoding, err := extractEncoding(l, data)
 	if err != nil {
 		return nil, err
 	}
-	langCode := string(data[1:4])
-
-	if langCode == "xxx" || langCode == "XXX" || langCode == "\x00\x00\x00" {
-		langCode = "ENG"
-	}
-
-	ftf.language, err = language.ParseBase(langCode)
+	ftf.language, err = readLanguage(data[1:4])
 	if err != nil {
-		glog.Errorf("Bad language: %v %v", langCode, hex.EncodeToString(data))
+		glog.Errorf("Bad language: %v %v", string(data[1:4]), hex.EncodeToString(data))
 		return nil, err
 	}
 
@@ -40,6 +38,9 @@ func newFullTextFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error)
 	if err != nil {
 		return nil, err
 	}
+	if i+4 > l {
+		return nil, ErrTooShort
+	}
 	ftf.description, err = decodeString(description, encoding)
 	if err != nil {
 		return nil, err
@@ -60,8 +61,12 @@ func newFullTextFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error)
 func newDescribedFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
 	ftf := &FullTextFrame{}
 	ftf.header = header
+	ftf.described = true
 
 	l := len(data)
+	if l < 1 {
+		return nil, ErrTooShort
+	}
 	textEncoding, encoding, err := extractEncoding(l, data)
 	if err != nil {
 		return nil, err
@@ -71,6 +76,9 @@ func newDescribedFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error
 	if err != nil {
 		return nil, err
 	}
+	if i > l {
+		return nil, ErrTooShort
+	}
 	ftf.description, err = decodeString(description, encoding)
 	if err != nil {
 		return nil, err
@@ -88,6 +96,21 @@ func newDescribedFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error
 	return ftf, nil
 }
 
+func NewFullTextFrame(id string, lang language.Base, description string, text string) *FullTextFrame {
+	ftf := &FullTextFrame{}
+	ftf.header = newFrameHeader(id, 0, 0, uint32(len(text)))
+	ftf.language = lang
+	ftf.description = description
+	ftf.text = text
+	return ftf
+}
+
+func NewDescribedFrame(id string, description string, text string) *FullTextFrame {
+	ftf := NewFullTextFrame(id, language.Base{}, description, text)
+	ftf.described = true
+	return ftf
+}
+
 func (ftf *FullTextFrame) String() string {
 	return ftf.text
 }
@@ -103,3 +126,37 @@ func (ftf *FullTextFrame) Description() string {
 func (ftf *FullTextFrame) Language() language.Base {
 	return ftf.language
 }
+
+func (ftf *FullTextFrame) encode(version uint8) ([]byte, error) {
+	textEncoding := encodingForVersion(version, ftf.description, ftf.text)
+	b := []byte{byte(textEncoding)}
+	if !ftf.described {
+		b = append(b, ftf.languageCode()...)
+	}
+	b, err := appendString(b, ftf.description, textEncoding, true)
+	if err != nil {
+		return nil, err
+	}
+	return appendString(b, ftf.text, textEncoding, false)
+}
+
+func (ftf *FullTextFrame) languageCode() string {
+	return languageCode(ftf.language)
+}
+
+// readLanguage parses the three letter language code used by comment and
+// lyrics frames, treating the common placeholders as English.
+func readLanguage(code []byte) (language.Base, error) {
+	langCode := string(code)
+	if langCode == "xxx" || langCode == "XXX" || langCode == "\x00\x00\x00" {
+		langCode = "ENG"
+	}
+	return language.ParseBase(langCode)
+}
+
+func languageCode(lang language.Base) string {
+	if lang == (language.Base{}) {
+		return "eng"
+	}
+	return lang.ISO3()
+}
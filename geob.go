@@ -0,0 +1,120 @@
+package id3
+
+import (
+	"fmt"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// GeneralObjectFrame is a GEOB frame, which encapsulates a file of any type
+// such as the cue and beat grid data DJ software stores in tags.
+type GeneralObjectFrame struct {
+	frameBase
+
+	mime        string
+	filename    string
+	description string
+	data        []byte
+}
+
+func newGeneralObjectFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	gof := &GeneralObjectFrame{}
+	gof.header = header
+
+	l := len(data)
+	if l < 4 {
+		return nil, ErrTooShort
+	}
+	textEncoding, encoding, err := extractEncoding(l, data)
+	if err != nil {
+		return nil, err
+	}
+	mime, i, err := trimForEncoding(l, data, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	gof.mime, err = decodeString(mime, charmap.Windows1252)
+	if err != nil {
+		return nil, err
+	}
+	if i > l {
+		return nil, ErrTooShort
+	}
+	filename, j, err := trimForEncoding(l-i, data[i:], textEncoding, false)
+	if err != nil {
+		return nil, err
+	}
+	gof.filename, err = decodeString(filename, encoding)
+	if err != nil {
+		return nil, err
+	}
+	i += j
+	if i > l {
+		return nil, ErrTooShort
+	}
+	description, j, err := trimForEncoding(l-i, data[i:], textEncoding, false)
+	if err != nil {
+		return nil, err
+	}
+	gof.description, err = decodeString(description, encoding)
+	if err != nil {
+		return nil, err
+	}
+	i += j
+	if i > l {
+		return nil, ErrTooShort
+	}
+	gof.data = data[i:]
+	return gof, nil
+}
+
+func NewGeneralObjectFrame(id string, mime string, filename string, description string, data []byte) *GeneralObjectFrame {
+	gof := &GeneralObjectFrame{}
+	gof.header = newFrameHeader(id, 0, 0, uint32(len(data)))
+	gof.mime = mime
+	gof.filename = filename
+	gof.description = description
+	gof.data = data
+	return gof
+}
+
+func (gof *GeneralObjectFrame) MIMEType() string {
+	return gof.mime
+}
+
+func (gof *GeneralObjectFrame) Filename() string {
+	return gof.filename
+}
+
+func (gof *GeneralObjectFrame) Description() string {
+	return gof.description
+}
+
+func (gof *GeneralObjectFrame) Data() []byte {
+	return gof.data
+}
+
+func (gof *GeneralObjectFrame) String() string {
+	return fmt.Sprintf("%v (%v, %v bytes)", gof.description, gof.mime, len(gof.data))
+}
+
+func (gof *GeneralObjectFrame) Bytes() []byte {
+	return gof.data
+}
+
+func (gof *GeneralObjectFrame) encode(version uint8) ([]byte, error) {
+	textEncoding := encodingForVersion(version, gof.filename, gof.description)
+	b, err := appendString([]byte{byte(textEncoding)}, gof.mime, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	b, err = appendString(b, gof.filename, textEncoding, true)
+	if err != nil {
+		return nil, err
+	}
+	b, err = appendString(b, gof.description, textEncoding, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, gof.data...), nil
+}
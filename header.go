@@ -2,7 +2,10 @@ package id3
 
 import "io"
 
-const headerSize uint32 = 10
+const (
+	headerSize uint32 = 10
+	footerSize uint32 = 10
+)
 
 type Header struct {
 	version  uint8
@@ -14,6 +17,16 @@ type Header struct {
 }
 
 func newHeader(r io.ReadSeeker) (*Header, error) {
+	return readHeader(r, "ID3")
+}
+
+// newFooter reads the footer that ends a v2.4 tag. It mirrors the header,
+// but starts with "3DI".
+func newFooter(r io.ReadSeeker) (*Header, error) {
+	return readHeader(r, "3DI")
+}
+
+func readHeader(r io.ReadSeeker, identifier string) (*Header, error) {
 	header := make([]byte, headerSize)
 	n, err := io.ReadFull(r, header)
 	if err != nil {
@@ -22,7 +35,7 @@ func newHeader(r io.ReadSeeker) (*Header, error) {
 	if uint32(n) < headerSize {
 		return nil, ErrTooShort
 	}
-	if string(header[:3]) != "ID3" {
+	if string(header[:3]) != identifier {
 		return nil, ErrNoHeader
 	}
 	h := &Header{
@@ -34,6 +47,15 @@ func newHeader(r io.ReadSeeker) (*Header, error) {
 	return h, nil
 }
 
+// tagSize returns the size of the whole tag, including header and footer.
+func (header *Header) tagSize() uint32 {
+	size := headerSize + header.frameSize
+	if header.version >= 4 && header.HasFooter() {
+		size += footerSize
+	}
+	return size
+}
+
 func (header *Header) Size() uint32 {
 	return header.frameSize
 }
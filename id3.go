@@ -1,50 +1,37 @@
 package id3
 
 import (
-	"errors"
-	"fmt"
+	"bytes"
 	"io"
 	"os"
+
+	"github.com/golang/glog"
 )
 
+const maxSeekTags = 8
+
 func Read(r io.ReadSeeker) (*Tag, error) {
 	//glog.Infof("READING: %v", path)
 
+	var offset int64
 	header, err := newHeader(r)
-	if err != nil {
+	if err == ErrNoHeader {
+		// v2.4 tags may be appended to the end of the stream instead
+		offset, header, err = findAppendedTag(r)
 		if err == ErrNoHeader {
 			return readv1(r)
-
 		}
-		return nil, err
 	}
-
-	//glog.Infof("Unsynchronized: %v", header.Unsynchronized())
-
-	var extendedHeader *ExtendedHeader
-
-	size := header.Size()
-
-	if header.HasExtendedHeader() {
-		extendedHeader, err = newExtendedHeader(r)
-		if err != nil {
-			return nil, err
-		}
-		size -= extendedHeader.size
+	if err != nil {
+		return nil, err
 	}
 
-	tag := newTag(header, extendedHeader)
-
-	switch header.version {
-	case 2:
-		tag.readV2(size, version22Params, r)
-	case 3:
-		tag.readV2(size, version23Params, r)
-	case 4:
-		tag.readV2(size, version24Params, r)
-	default:
-		return nil, errors.New(fmt.Sprintf("Unknown major revision: %v", header.version))
+	tag, err := readV2Tag(header, r)
+	if err != nil {
+		return nil, err
 	}
+	tag.offset = offset
+	tag.followSeek(r)
 
 	if tag.missingCoreInfo() {
 		id3v1Tag, err := readv1(r)
@@ -61,6 +48,7 @@ func readAll(path string, parseBoth bool) (*Tag, *Tag, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	defer r.Close()
 
 	header, err := newHeader(r)
 	if err != nil {
@@ -72,37 +60,143 @@ func readAll(path string, parseBoth bool) (*Tag, *Tag, error) {
 		return nil, nil, err
 	}
 
-	//glog.Infof("Unsynchronized: %v", header.Unsynchronized())
+	var id3v1Tag *Tag
+	tag, err := readV2Tag(header, r)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	var extendedHeader *ExtendedHeader
+	if parseBoth {
+		id3v1Tag, err = readv1(r)
+	}
+
+	return tag, id3v1Tag, nil
+}
+
+func readV2Tag(header *Header, r io.ReadSeeker) (*Tag, error) {
+	params, err := paramsForVersion(header.version)
+	if err != nil {
+		return nil, err
+	}
 
 	size := header.Size()
 
-	if header.HasExtendedHeader() {
-		extendedHeader, err = newExtendedHeader(r)
+	if header.Unsynchronized() && header.version < 4 {
+		// Before v2.4 the whole tag is unsynchronised, including the
+		// extended header, so undo it before parsing anything
+		data := make([]byte, size)
+		_, err = io.ReadFull(r, data)
+		if err != nil {
+			return nil, err
+		}
+		data = resync(data)
+		size = uint32(len(data))
+		r = bytes.NewReader(data)
+	}
+
+	var extendedHeader *ExtendedHeader
+
+	// In v2.2 this flag marks a compressed tag rather than an extended header
+	if header.HasExtendedHeader() && header.version >= 3 {
+		extendedHeader, err = newExtendedHeader(r, header.version, size)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		size -= extendedHeader.size
+		if _, ok := extendedHeader.CRC(); ok {
+			data := make([]byte, size)
+			_, err = io.ReadFull(r, data)
+			if err != nil {
+				return nil, err
+			}
+			if !extendedHeader.verifyCRC(header.version, data) {
+				glog.Errorf("Tag CRC mismatch")
+			}
+			r = bytes.NewReader(data)
+		}
 	}
 
-	var id3v1Tag *Tag
 	tag := newTag(header, extendedHeader)
+	tag.readV2(size, params, r)
+	return tag, nil
+}
 
-	switch header.version {
-	case 2:
-		tag.readV2(size, version22Params, r)
-	case 3:
-		tag.readV2(size, version23Params, r)
-	case 4:
-		tag.readV2(size, version24Params, r)
-	default:
-		return nil, nil, errors.New(fmt.Sprintf("Unknown major revision: %v", header.version))
+// findAppendedTag looks for the footer of a tag appended to the end of the
+// stream, either right at the end or just before an ID3v1 tag, and returns the
+// offset and header of the tag it closes.
+func findAppendedTag(r io.ReadSeeker) (int64, *Header, error) {
+	end, err := r.Seek(0, os.SEEK_END)
+	if err != nil {
+		return 0, nil, err
 	}
+	for _, pos := range []int64{end - int64(footerSize), end - v1TagSize - int64(footerSize)} {
+		if pos < int64(headerSize) {
+			continue
+		}
+		_, err = r.Seek(pos, os.SEEK_SET)
+		if err != nil {
+			return 0, nil, err
+		}
+		footer, err := newFooter(r)
+		if err != nil {
+			continue
+		}
+		offset := pos - int64(footer.Size()) - int64(headerSize)
+		if offset < 0 {
+			continue
+		}
+		_, err = r.Seek(offset, os.SEEK_SET)
+		if err != nil {
+			return 0, nil, err
+		}
+		header, err := newHeader(r)
+		if err != nil {
+			continue
+		}
+		return offset, header, nil
+	}
+	return 0, nil, ErrNoHeader
+}
 
-	if parseBoth {
-		id3v1Tag, err = readv1(r)
+// followSeek reads the further tags that SEEK frames point to and applies
+// them to the tag as updates.
+func (tag *Tag) followSeek(r io.ReadSeeker) {
+	current := tag
+	pos := tag.offset
+	for i := 0; i < maxSeekTags; i++ {
+		seek, ok := current.Frame(FrameSeek).(*SeekFrame)
+		if !ok {
+			return
+		}
+		pos += int64(current.Header.tagSize()) + int64(seek.Offset())
+		_, err := r.Seek(pos, os.SEEK_SET)
+		if err != nil {
+			glog.Errorf("Error seeking to next tag: %v", err)
+			return
+		}
+		header, err := newHeader(r)
+		if err != nil {
+			glog.Errorf("Error reading next tag at %v: %v", pos, err)
+			return
+		}
+		next, err := readV2Tag(header, r)
+		if err != nil {
+			glog.Errorf("Error reading next tag at %v: %v", pos, err)
+			return
+		}
+		next.offset = pos
+		tag.update(next)
+		current = next
 	}
+}
 
-	return tag, id3v1Tag, nil
+// update replaces the frames of the tag with those of a later tag that
+// updates it.
+func (tag *Tag) update(next *Tag) {
+	for _, id := range next.FrameIDs() {
+		tag.RemoveFrames(FrameKind(id))
+	}
+	for _, frame := range next.frames {
+		tag.addFrame(frame)
+	}
 }
@@ -4,15 +4,23 @@
 package id3
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/davecheney/profile"
 	"github.com/golang/glog"
+	"golang.org/x/text/language"
 )
 
 type testData struct {
@@ -51,12 +59,12 @@ var root string
 func init() {
 	defer profile.Start(profile.CPUProfile).Stop()
 	flag.StringVar(&root, "root", "M:\\Music", "The root to parse")
-	flag.Parse()
-
 }
 
 func TestAll(t *testing.T) {
-
+	if _, err := os.Stat(root); err != nil {
+		t.Skipf("skipping; %v", err)
+	}
 	filepath.Walk(root, walkFunc)
 }
 
@@ -284,6 +292,46 @@ func testReadV2(testData *testData, t *testing.T) error {
 	return nil
 }
 
+func TestMarshal(t *testing.T) {
+	for _, path := range []string{"test/test.mp3", "test/v1andv24tags.mp3", "test/v23unicodetags.mp3"} {
+		r, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag, err := Read(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("%v: %v", path, err)
+		}
+		for _, version := range []uint8{3, 4} {
+			b, err := tag.Marshal(version)
+			if err != nil {
+				t.Errorf("%v: v2.%v: %v", path, version, err)
+				continue
+			}
+			tag2, err := Read(bytes.NewReader(b))
+			if err != nil {
+				t.Errorf("%v: v2.%v: %v", path, version, err)
+				continue
+			}
+			if tag2.Header.Version() != version {
+				t.Errorf("%v: wrote version %v, read %v", path, version, tag2.Header.Version())
+			}
+			if tag2.Title() != tag.Title() || tag2.Artist() != tag.Artist() || tag2.Album() != tag.Album() {
+				t.Errorf("%v: v2.%v: core frames differ after round trip", path, version)
+			}
+			if len(tag2.frames) != len(tag.frames) {
+				t.Errorf("%v: v2.%v: wrote %v frames, read %v", path, version, len(tag.frames), len(tag2.frames))
+			}
+			for i, frame := range tag.frames {
+				if i < len(tag2.frames) && frame.String() != tag2.frames[i].String() {
+					t.Errorf("%v: v2.%v: frame %v: expected %q, got %q", path, version, frame.Id(), frame.String(), tag2.frames[i].String())
+				}
+			}
+		}
+	}
+}
+
 /*func testReadV2(testData *testData, t *testing.T) error {
 	fmt.Println(testData.path)
 	file, err := Read(testData.path)
@@ -371,102 +419,503 @@ func testReadV1(testData *testData, t *testing.T) error {
 		}
 	}
 	return nil
+}*/
+
+const testFile = "test/test.mp3"
+
+func TestFrames(t *testing.T) {
+	tag := newTag(&Header{version: 3}, nil)
+	tag.SetTitle("Title")
+	tag.AddFrame(NewDescribedFrame("TXXX", "one", "1"))
+	tag.AddComment(language.MustParseBase("eng"), "", "Comment")
+	tag.AddFrame(NewDescribedFrame("TXXX", "two", "2"))
+	tag.AddFrame(NewDescribedFrame("TXXX", "three", "3"))
+
+	if n := len(tag.Frames()); n != 5 {
+		t.Errorf("expected 5 frames, got %v", n)
+	}
+	ids := tag.FrameIDs()
+	if strings.Join(ids, ",") != "TIT2,TXXX,COMM" {
+		t.Errorf("incorrect frame IDs, %v", ids)
+	}
+	txxx := tag.FramesByID("TXXX")
+	if len(txxx) != 3 {
+		t.Fatalf("expected 3 TXXX frames, got %v", len(txxx))
+	}
+	for i, text := range []string{"1", "2", "3"} {
+		if s := txxx[i].String(); s != text {
+			t.Errorf("TXXX %v: expected %v, got %v", i, text, s)
+		}
+	}
+	if f := tag.Frame("TXXX"); f != txxx[0] {
+		t.Errorf("Frame did not return the first TXXX frame")
+	}
+	if f := tag.Frame("APIC"); f != nil {
+		t.Errorf("unexpected frame %v", f.Id())
+	}
 }
 
-func TestOpen(t *testing.T) {
-	file, err := Open(testFile)
-	if err != nil {
-		t.Errorf("Open: unable to open file")
-		return
+func TestFrameKinds(t *testing.T) {
+	for _, path := range []string{"test/obsolete.mp3", "test/v24tagswithalbumimage.mp3"} {
+		r, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag, err := Read(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("%v: %v", path, err)
+		}
+		frame := tag.Frame(FrameComposer)
+		if frame == nil || !strings.HasPrefix(frame.String(), "COMPOSER") {
+			t.Errorf("%v: composer not found", path)
+		}
+		if tag.Frame(FrameAttachedPicture) == nil {
+			t.Errorf("%v: picture not found", path)
+		}
+		if tag.Frame("TIT2") != tag.Frame("TT2") {
+			t.Errorf("%v: frame IDs from different versions disagree", path)
+		}
 	}
 
-	tag, ok := file.Tagger.(*v2.Tag)
+	if id := FrameAttachedPicture.ID(2); id != "PIC" {
+		t.Errorf("incorrect v2.2 ID for APIC, %v", id)
+	}
+	if id := FrameYear.ID(4); id != "" {
+		t.Errorf("TYER should not exist in v2.4, got %v", id)
+	}
+	if id := FrameKind("TT2").ID(3); id != "TIT2" {
+		t.Errorf("incorrect v2.3 ID for TT2, %v", id)
+	}
+}
+
+func TestUnsynchronization(t *testing.T) {
+	picture := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 0xFF, 0x00, 0xFF, 0xFF, 0xFE, 0x01, 0xFF}
+	for path, title := range map[string]string{
+		"test/v23unsynchronized.mp3":       "Unsynchronized v2.3",
+		"test/v24unsynchronizedframes.mp3": "Unsynchronized v2.4",
+	} {
+		r, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag, err := Read(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("%v: %v", path, err)
+		}
+		if s := tag.Title(); s != title {
+			t.Errorf("%v: incorrect title, %q", path, s)
+		}
+		if s := tag.Artist(); s != "Art" {
+			t.Errorf("%v: incorrect artist, %q", path, s)
+		}
+		pf, ok := tag.Frame(FrameAttachedPicture).(*PictureFrame)
+		if !ok {
+			t.Errorf("%v: missing picture", path)
+			continue
+		}
+		if !bytes.Equal(pf.Bytes(), picture) {
+			t.Errorf("%v: incorrect picture data, %x", path, pf.Bytes())
+		}
+	}
+
+	// Sub-frames are resynchronised along with their chapter, not again
+	r, err := os.Open("test/v24unsynchronizedchapter.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := Read(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chapters := tag.Chapters()
+	if len(chapters) != 1 {
+		t.Fatalf("incorrect chapters, %v", chapters)
+	}
+	if s := chapters[0].Title(); s != "Unsynchronized chapter" {
+		t.Errorf("incorrect chapter title, %q", s)
+	}
+	pf, ok := chapters[0].SubFrame(FramePrivate).(*PrivateFrame)
 	if !ok {
-		t.Errorf("Open: incorrect tagger type")
-		return
+		t.Fatalf("missing private sub-frame")
 	}
+	if data := pf.Data(); !bytes.Equal(data, []byte{0xFF, 0x00, 0x01, 0xFF, 0xE0}) {
+		t.Errorf("incorrect private sub-frame data, %x", data)
+	}
+}
 
-	if s := tag.Artist(); s != "Paloalto" {
-		t.Errorf("Open: incorrect artist, %v", s)
-		return
+func TestCompressedFrames(t *testing.T) {
+	for _, version := range []uint8{3, 4} {
+		tag := newTag(&Header{version: version}, nil)
+		title := NewTextFrame("TIT2", strings.Repeat("Compressed ", 100))
+		title.SetCompressed(true)
+		tag.AddFrame(title)
+		artist := NewTextFrame("TPE1", "Grouped")
+		artist.SetGroupID(0x80)
+		tag.AddFrame(artist)
+		album := NewTextFrame("TALB", "Both")
+		album.SetCompressed(true)
+		album.SetGroupID(0x81)
+		tag.AddFrame(album)
+
+		b, err := tag.Marshal(version)
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		if bytes.Contains(b, []byte("Compressed Compressed")) {
+			t.Errorf("v2.%v: title was not compressed", version)
+		}
+		tag, err = Read(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		if s := tag.Title(); s != title.String() {
+			t.Errorf("v2.%v: incorrect title, %q", version, s)
+		}
+		if s := tag.Artist(); s != "Grouped" {
+			t.Errorf("v2.%v: incorrect artist, %q", version, s)
+		}
+		if s := tag.Album(); s != "Both" {
+			t.Errorf("v2.%v: incorrect album, %q", version, s)
+		}
+		if !tag.Frame(FrameTitle).Compressed() {
+			t.Errorf("v2.%v: title not marked as compressed", version)
+		}
+		if id, ok := tag.Frame(FrameArtist).GroupID(); !ok || id != 0x80 {
+			t.Errorf("v2.%v: incorrect artist group, %v %v", version, id, ok)
+		}
+		if id, ok := tag.Frame(FrameAlbum).GroupID(); !ok || id != 0x81 {
+			t.Errorf("v2.%v: incorrect album group, %v %v", version, id, ok)
+		}
 	}
 
-	if s := tag.Title(); s != "Nice Life (Feat. Basick)" {
-		t.Errorf("Open: incorrect title, %v", s)
-		return
+	// Data that inflates to more or less than the declared size is rejected
+	compressed, err := compress(bytes.Repeat([]byte("x"), 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []uint32{10, 200} {
+		data := make([]byte, 4, 4+len(compressed))
+		binary.BigEndian.PutUint32(data, size)
+		data = append(data, compressed...)
+		fh := newFrameHeader("TIT2", 0, formatCompressedV23, uint32(len(data)))
+		if _, err = fh.readFormat(3, false, data); err != ErrDecompressedSize {
+			t.Errorf("declared size %v: expected ErrDecompressedSize, got %v", size, err)
+		}
 	}
+}
 
-	if s := tag.Album(); s != "Chief Life" {
-		t.Errorf("Open: incorrect album, %v", s)
-		return
+func TestAppendedTags(t *testing.T) {
+	for _, v := range []struct {
+		path   string
+		title  string
+		artist string
+		album  string
+	}{
+		{"test/v24appendedtag.mp3", "Appended title", "Appended artist", "V1 ALBUM"},
+		{"test/v24seek.mp3", "Updated title", "Original artist", "Updated album"},
+	} {
+		r, err := os.Open(v.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag, err := Read(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("%v: %v", v.path, err)
+		}
+		if s := tag.Title(); s != v.title {
+			t.Errorf("%v: incorrect title, %q", v.path, s)
+		}
+		if s := tag.Artist(); s != v.artist {
+			t.Errorf("%v: incorrect artist, %q", v.path, s)
+		}
+		if s := tag.Album(); s != v.album {
+			t.Errorf("%v: incorrect album, %q", v.path, s)
+		}
 	}
+}
 
-	parsedFrame := file.Frame("COMM")
-	resultFrame, ok := parsedFrame.(*v2.UnsynchTextFrame)
-	if !ok {
-		t.Error("Couldn't cast frame")
-		return
+func TestExtendedHeader(t *testing.T) {
+	read := func(path string) *Tag {
+		r, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		tag, err := Read(r)
+		if err != nil {
+			t.Fatalf("%v: %v", path, err)
+		}
+		if tag.ExtendedHeader == nil {
+			t.Fatalf("%v: missing extended header", path)
+		}
+		return tag
 	}
 
-	expected := "✓"
-	actual := resultFrame.Description()
+	tag := read("test/v23extendedheader.mp3")
+	if s := tag.Artist(); s != "CRC artist" {
+		t.Errorf("incorrect artist, %q", s)
+	}
+	ext := tag.ExtendedHeader
+	if _, ok := ext.CRC(); !ok || !ext.CRCValid() {
+		t.Errorf("v2.3 CRC missing or invalid")
+	}
+	if n := ext.PaddingSize(); n != 32 {
+		t.Errorf("incorrect padding size, %v", n)
+	}
 
-	if expected != actual {
-		t.Errorf("Expected %x, got %x", expected, actual)
-		return
+	tag = read("test/v24extendedheader.mp3")
+	if s := tag.Title(); s != "Restricted title" {
+		t.Errorf("incorrect title, %q", s)
+	}
+	ext = tag.ExtendedHeader
+	if !ext.IsUpdate() {
+		t.Errorf("v2.4 update flag not set")
+	}
+	if _, ok := ext.CRC(); !ok || !ext.CRCValid() {
+		t.Errorf("v2.4 CRC missing or invalid")
+	}
+	expected := Restrictions{TagSizeMax64Frames128KB, TextEncodingUnrestricted, TextFieldSizeMax30, ImageEncodingPNGOrJPEG, ImageSizeMax64}
+	if r := ext.Restrictions(); r == nil || *r != expected {
+		t.Errorf("incorrect restrictions, %+v", r)
+	}
+	if ext.verifyCRC(4, []byte("corrupted")) || ext.CRCValid() {
+		t.Errorf("CRC matched corrupted data")
 	}
 
-	actual = resultFrame.Text()
-	if expected != actual {
-		t.Errorf("Expected %q, got %q", expected, actual)
-		return
+	// Extended header sizes larger than the tag are rejected before the
+	// extended header is read
+	for _, data := range []string{
+		"4944330300400000000effffff0000000000000000000000",
+		"4944330400400000000e7f7f7f7f01000000000000000000",
+	} {
+		b, err := hex.DecodeString(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = Read(bytes.NewReader(b)); err != ErrCorruptExtendedHeader {
+			t.Errorf("%v: expected ErrCorruptExtendedHeader, got %v", data, err)
+		}
 	}
 }
 
-func TestClose(t *testing.T) {
-	before, err := ioutil.ReadFile(testFile)
+func TestConvertTo(t *testing.T) {
+	r, err := os.Open("test/obsolete.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := Read(r)
+	r.Close()
 	if err != nil {
-		t.Errorf("test file error")
+		t.Fatal(err)
 	}
+	title := tag.Title()
 
-	file, err := Open(testFile)
+	for _, version := range []uint8{3, 4, 3, 2} {
+		dropped, err := tag.ConvertTo(version)
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		if len(dropped) > 0 {
+			t.Errorf("v2.%v: unexpected dropped frames %v", version, len(dropped))
+		}
+		b, err := tag.Marshal(version)
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		tag, err = Read(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		if s := tag.Title(); s != title {
+			t.Errorf("v2.%v: incorrect title, %v", version, s)
+		}
+		if s := tag.Year(); s != "2009" {
+			t.Errorf("v2.%v: incorrect year, %v", version, s)
+		}
+		picture, ok := tag.Frame(FrameAttachedPicture).(*PictureFrame)
+		if !ok || picture.mime != "image/png" || len(picture.data) != 236734 {
+			t.Errorf("v2.%v: picture not converted", version)
+		}
+	}
+}
+
+func TestConvertDates(t *testing.T) {
+	tag := newTag(&Header{version: 3}, nil)
+	tag.AddFrame(NewTextFrame("TYER", "2013"))
+	tag.AddFrame(NewTextFrame("TDAT", "2511"))
+	tag.AddFrame(NewTextFrame("TIME", "1230"))
+	tag.AddFrame(NewTextFrame("TRDA", "November 25th"))
+
+	dropped, err := tag.ConvertTo(4)
 	if err != nil {
-		t.Errorf("Close: unable to open file")
+		t.Fatal(err)
+	}
+	if len(dropped) != 1 || dropped[0].Id() != "TRDA" {
+		t.Errorf("expected TRDA to be dropped, got %v", dropped)
+	}
+	if ids := strings.Join(tag.FrameIDs(), ","); ids != "TDRC" {
+		t.Errorf("incorrect frames, %v", ids)
+	}
+	if s := tag.Year(); s != "2013-11-25T12:30" {
+		t.Errorf("incorrect recording time, %v", s)
+	}
+
+	_, err = tag.ConvertTo(3)
+	if err != nil {
+		t.Fatal(err)
 	}
-	beforeCutoff := file.originalSize
+	if ids := strings.Join(tag.FrameIDs(), ","); ids != "TYER,TDAT,TIME" {
+		t.Errorf("incorrect frames, %v", ids)
+	}
+	for kind, value := range map[FrameKind]string{FrameYear: "2013", FrameDate: "2511", FrameTime: "1230"} {
+		if s := tag.Frame(kind).String(); s != value {
+			t.Errorf("%v: expected %v, got %v", kind, value, s)
+		}
+	}
+}
 
-	file.SetArtist("Paloalto")
-	file.SetTitle("Test test test test test test")
+func TestSetters(t *testing.T) {
+	for _, v := range []struct {
+		version uint8
+		titleId string
+	}{{2, "TT2"}, {3, "TIT2"}, {4, "TIT2"}} {
+		tag := newTag(&Header{version: v.version}, nil)
+		tag.SetTitle("First")
+		tag.SetTitle("Second")
+		tag.AddComment(language.MustParseBase("eng"), "", "One")
+		tag.AddComment(language.MustParseBase("eng"), "", "Two")
+
+		if s := tag.Title(); s != "Second" {
+			t.Errorf("v2.%v: incorrect title, %v", v.version, s)
+		}
+		if n := len(tag.frames); n != 3 {
+			t.Errorf("v2.%v: expected 3 frames, got %v", v.version, n)
+		}
+		if id := tag.frames[0].Id(); id != v.titleId {
+			t.Errorf("v2.%v: incorrect title frame ID, %v", v.version, id)
+		}
+		if n := len(tag.Comments()); n != 2 {
+			t.Errorf("v2.%v: expected 2 comments, got %v", v.version, n)
+		}
 
-	afterCutoff := file.Size()
+		tag.RemoveFrames(FrameTitle)
+		if s := tag.Title(); s != "" {
+			t.Errorf("v2.%v: title not removed, %v", v.version, s)
+		}
+		if n := len(tag.frames); n != 2 {
+			t.Errorf("v2.%v: expected 2 frames after removal, got %v", v.version, n)
+		}
+	}
+}
 
-	if err := file.Close(); err != nil {
-		t.Errorf("Close: unable to close file")
+func copyToTemp(t *testing.T, path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tempFile, err := ioutil.TempFile("", "id3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tempFile.Write(data)
+	tempFile.Close()
+	if err != nil {
+		t.Fatal(err)
 	}
+	return tempFile.Name()
+}
 
-	after, err := ioutil.ReadFile(testFile)
+func TestOpen(t *testing.T) {
+	file, err := Open(testFile)
 	if err != nil {
-		t.Errorf("Close: unable to reopen file")
+		t.Fatalf("Open: unable to open file: %v", err)
+	}
+	defer file.Close()
+
+	if s := file.Artist(); s != "Paloalto" {
+		t.Errorf("Open: incorrect artist, %v", s)
+	}
+
+	if s := file.Title(); s != "Nice Life (Feat. Basick)" {
+		t.Errorf("Open: incorrect title, %v", s)
+	}
+
+	if s := file.Album(); s != "Chief Life" {
+		t.Errorf("Open: incorrect album, %v", s)
 	}
 
-	if !bytes.Equal(before[beforeCutoff:], after[afterCutoff:]) {
-		t.Errorf("Close: nontag data lost on close")
+	resultFrame, ok := file.Frame(FrameComments).(*FullTextFrame)
+	if !ok {
+		t.Fatalf("Open: couldn't cast frame, %T", file.Frame(FrameComments))
+	}
+	expected := "✓"
+	if actual := resultFrame.Description(); actual != expected {
+		t.Errorf("Open: expected %x, got %x", expected, actual)
 	}
+	if actual := resultFrame.String(); actual != expected {
+		t.Errorf("Open: expected %q, got %q", expected, actual)
+	}
+}
+
+func TestClose(t *testing.T) {
+	for _, title := range []string{"Test test test test test test", strings.Repeat("Test ", 20000)} {
+		path := copyToTemp(t, testFile)
+		defer os.Remove(path)
+
+		before, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		file, err := Open(path)
+		if err != nil {
+			t.Fatalf("Close: unable to open file: %v", err)
+		}
+		beforeCutoff := file.originalSize
+
+		file.SetTitle(title)
+
+		if err := file.Close(); err != nil {
+			t.Fatalf("Close: unable to close file: %v", err)
+		}
+		afterCutoff := file.originalSize
+
+		after, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Close: unable to reopen file: %v", err)
+		}
 
-	if err := ioutil.WriteFile(testFile, before, 0666); err != nil {
-		t.Errorf("Close: unable to write original contents to test file")
+		if !bytes.Equal(before[beforeCutoff:], after[afterCutoff:]) {
+			t.Errorf("Close: nontag data lost on close")
+		}
+
+		file, err = Open(path)
+		if err != nil {
+			t.Fatalf("Close: unable to open saved file: %v", err)
+		}
+		if s := file.Title(); s != title {
+			t.Errorf("Close: incorrect title, %v", s)
+		}
+		file.Close()
 	}
 }
 
 func TestReadonly(t *testing.T) {
-	before, err := ioutil.ReadFile(testFile)
+	path := copyToTemp(t, testFile)
+	defer os.Remove(path)
+
+	before, err := ioutil.ReadFile(path)
 	if err != nil {
-		t.Errorf("test file error")
+		t.Fatal(err)
 	}
 
-	file, err := Open(testFile)
+	file, err := Open(path)
 	if err != nil {
-		t.Errorf("Readonly: unable to open file")
+		t.Fatalf("Readonly: unable to open file: %v", err)
 	}
 
 	file.Title()
@@ -477,12 +926,12 @@ func TestReadonly(t *testing.T) {
 	file.Comments()
 
 	if err := file.Close(); err != nil {
-		t.Errorf("Readonly: unable to close file")
+		t.Errorf("Readonly: unable to close file: %v", err)
 	}
 
-	after, err := ioutil.ReadFile(testFile)
+	after, err := ioutil.ReadFile(path)
 	if err != nil {
-		t.Errorf("Readonly: unable to reopen file")
+		t.Fatalf("Readonly: unable to reopen file: %v", err)
 	}
 
 	if !bytes.Equal(before, after) {
@@ -490,105 +939,1143 @@ func TestReadonly(t *testing.T) {
 	}
 }
 
-func TestAddTag(t *testing.T) {
-	tempFile, err := ioutil.TempFile("", "notag")
+func TestReadonlyOpen(t *testing.T) {
+	path := copyToTemp(t, testFile)
+	defer os.Remove(path)
+	err := os.Chmod(path, 0444)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	file, err := Open(tempFile.Name())
+	before, err := ioutil.ReadFile(path)
 	if err != nil {
-		t.Errorf("AddTag: unable to open empty file")
+		t.Fatal(err)
 	}
 
-	tag := file.Tagger
-
-	if tag == nil {
-		t.Errorf("AddTag: no tag added to file")
+	file, err := Open(path)
+	if err != nil {
+		t.Fatalf("ReadonlyOpen: unable to open file: %v", err)
+	}
+	if !file.readOnly {
+		// Running as root, say; the rest of the read-only handling still applies
+		t.Log("ReadonlyOpen: file permissions are not enforced for this user")
+		file.readOnly = true
+	}
+	if s := file.Title(); s != "Nice Life (Feat. Basick)" {
+		t.Errorf("ReadonlyOpen: incorrect title, %v", s)
 	}
 
-	file.SetArtist("Michael")
-
-	err = file.Close()
-	if err != nil {
-		t.Errorf("AddTag: error closing new file")
+	file.SetTitle("Changed")
+	if err := file.Save(); err != ErrReadOnly {
+		t.Errorf("ReadonlyOpen: expected ErrReadOnly from Save, got %v", err)
+	}
+	if err := file.Close(); err != ErrReadOnly {
+		t.Errorf("ReadonlyOpen: expected ErrReadOnly from Close, got %v", err)
 	}
 
-	reopenBytes, err := ioutil.ReadFile(tempFile.Name())
+	after, err := ioutil.ReadFile(path)
 	if err != nil {
-		t.Errorf("AddTag: error reopening file")
+		t.Fatal(err)
 	}
-
-	expectedBytes := tag.Bytes()
-	if !bytes.Equal(expectedBytes, reopenBytes) {
-		t.Errorf("AddTag: tag not written correctly: %v", reopenBytes)
+	if !bytes.Equal(before, after) {
+		t.Errorf("ReadonlyOpen: read-only file modified")
 	}
 }
 
-func TestUnsynchTextFrame_RoundTrip(t *testing.T) {
-	var (
-		err              error
-		tempfile         *os.File
-		f                *File
-		tagger           *v2.Tag
-		ft               v2.FrameType
-		utextFrame       *v2.UnsynchTextFrame
-		parsedFrame      v2.Framer
-		resultFrame      *v2.UnsynchTextFrame
-		ok               bool
-		expected, actual string
-	)
+func TestSaveAppendedTag(t *testing.T) {
+	path := copyToTemp(t, "test/v24appendedtag.mp3")
+	defer os.Remove(path)
 
-	tempfile, err = ioutil.TempFile("", "id3v2")
+	file, err := Open(path)
 	if err != nil {
 		t.Fatal(err)
 	}
+	file.SetTitle("Prepended title")
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
 
-	tagger = v2.NewTag(3)
-	ft = v2.V23FrameTypeMap["COMM"]
-	utextFrame = v2.NewUnsynchTextFrame(ft, "Comment", "Foo")
-	tagger.AddFrames(utextFrame)
-
-	_, err = tempfile.Write(tagger.Bytes())
-	tempfile.Close()
+	after, err := ioutil.ReadFile(path)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if bytes.Contains(after, []byte("Appended title")) || bytes.Contains(after, []byte("3DI")) {
+		t.Errorf("SaveAppendedTag: appended tag left at the end of the file")
+	}
+	if len(after) < v1TagSize || string(after[len(after)-v1TagSize:][:3]) != "TAG" {
+		t.Errorf("SaveAppendedTag: ID3v1 tag lost")
+	}
 
-	f, err = Open(tempfile.Name())
+	file, err = Open(path)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	parsedFrame = f.Frame("COMM")
-	if resultFrame, ok = parsedFrame.(*v2.UnsynchTextFrame); !ok {
-		t.Error("Couldn't cast frame")
-	} else {
-		expected = utextFrame.Description()
-		actual = resultFrame.Description()
-		if expected != actual {
-			t.Errorf("Expected %q, got %q", expected, actual)
-		}
+	defer file.Close()
+	if s := file.Title(); s != "Prepended title" {
+		t.Errorf("SaveAppendedTag: incorrect title, %q", s)
+	}
+	if s := file.Artist(); s != "Appended artist" {
+		t.Errorf("SaveAppendedTag: incorrect artist, %q", s)
 	}
 }
 
-func TestUTF16CommPanic(t *testing.T) {
-	osFile, err := os.Open(testFile)
+func TestOpenV1Tag(t *testing.T) {
+	path := copyToTemp(t, "test/v1tag.mp3")
+	defer os.Remove(path)
+
+	file, err := Open(path)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
+	}
+	if s := file.Title(); s != "TITLE1234567890123456789012345" {
+		t.Errorf("OpenV1Tag: incorrect title, %q", s)
+	}
+	if s := file.Artist(); s != "ARTIST123456789012345678901234" {
+		t.Errorf("OpenV1Tag: incorrect artist, %q", s)
+	}
+	file.SetAlbum("New album")
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
 	}
-	tempfile, err := ioutil.TempFile("", "utf16_comm")
+
+	file, err = Open(path)
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
-	io.Copy(tempfile, osFile)
-	osFile.Close()
-	tempfile.Close()
-	for i := 0; i < 2; i++ {
-		file, err := Open(tempfile.Name())
-		if err != nil {
-			t.Error(err)
-		}
-		file.Close()
+	defer file.Close()
+	if file.Header == nil || file.Header.Version() != 4 {
+		t.Fatalf("OpenV1Tag: v2.4 tag not written")
 	}
-}*/
+	if s := file.Title(); s != "TITLE1234567890123456789012345" {
+		t.Errorf("OpenV1Tag: ID3v1 title not saved, %q", s)
+	}
+	if s := file.Album(); s != "New album" {
+		t.Errorf("OpenV1Tag: incorrect album, %q", s)
+	}
+}
+
+func TestMergeV1Tag(t *testing.T) {
+	r, err := os.Open("test/v1tag.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, err := readv1(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := newTag(&Header{version: 3}, nil)
+	tag.SetArtist("v2 artist")
+	tag.mergeTag(v1)
+	if s := tag.Artist(); s != "v2 artist" {
+		t.Errorf("ID3v2 artist replaced, %q", s)
+	}
+	if frame := tag.Frame(FrameTitle); frame == nil || frame.Id() != "TIT2" {
+		t.Fatalf("ID3v1 title not merged as a v2.3 frame, %v", frame)
+	}
+	if id := v1.Frame(FrameTitle).Id(); id != "TT2" {
+		t.Errorf("ID3v1 tag changed by merging it, %v", id)
+	}
+
+	tag.RemoveFrames(FrameTitle)
+	if s := tag.Title(); s != "" {
+		t.Errorf("merged title not removed, %q", s)
+	}
+	if _, err := tag.ConvertTo(4); err != nil {
+		t.Fatal(err)
+	}
+	if s := tag.Album(); s != "ALBUM1234567890123456789012345" {
+		t.Errorf("merged album lost converting, %q", s)
+	}
+	if frame := tag.Frame(FrameRecordingTime); frame == nil || frame.String() != "2001" {
+		t.Errorf("merged year not converted, %v", frame)
+	}
+}
+
+func TestAddTag(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "notag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	file, err := Open(tempFile.Name())
+	if err != nil {
+		t.Fatalf("AddTag: unable to open empty file: %v", err)
+	}
+
+	tag := file.Tag
+
+	if tag == nil {
+		t.Fatalf("AddTag: no tag added to file")
+	}
+
+	file.SetArtist("Michael")
+
+	err = file.Close()
+	if err != nil {
+		t.Errorf("AddTag: error closing new file: %v", err)
+	}
+
+	reopenBytes, err := ioutil.ReadFile(tempFile.Name())
+	if err != nil {
+		t.Errorf("AddTag: error reopening file")
+	}
+
+	expectedBytes, err := tag.Marshal(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expectedBytes, reopenBytes) {
+		t.Errorf("AddTag: tag not written correctly: %v", reopenBytes)
+	}
+}
+
+func TestUnsynchTextFrame_RoundTrip(t *testing.T) {
+	tempfile, err := ioutil.TempFile("", "id3v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempfile.Name())
+
+	tag := newTag(&Header{version: 3}, nil)
+	utextFrame := NewFullTextFrame("COMM", language.MustParseBase("en"), "Comment", "Foo")
+	tag.AddFrame(utextFrame)
+	_, err = tag.WriteTo(tempfile)
+	tempfile.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Open(tempfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if resultFrame, ok := f.Frame(FrameComments).(*FullTextFrame); !ok {
+		t.Error("Couldn't cast frame")
+	} else {
+		if expected, actual := utextFrame.Description(), resultFrame.Description(); expected != actual {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+		if expected, actual := utextFrame.String(), resultFrame.String(); expected != actual {
+			t.Errorf("Expected %q, got %q", expected, actual)
+		}
+	}
+}
+
+func TestUTF16CommPanic(t *testing.T) {
+	path := copyToTemp(t, testFile)
+	defer os.Remove(path)
+	for i := 0; i < 2; i++ {
+		file, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := file.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestUTF16ByteOrder(t *testing.T) {
+	for _, v := range []struct {
+		data        string
+		description string
+		text        string
+	}{
+		// Without a BOM
+		{"01656e67006400000041", "d", "A"},
+		// With big-endian BOMs
+		{"01656e67feff00640000feff0041", "d", "A"},
+		// Each string with its own byte order
+		{"01656e67fffe64000000feff0041", "d", "A"},
+	} {
+		data, err := hex.DecodeString(v.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frame, err := newFullTextFrame(nil, newFrameHeader("COMM", 0, 0, uint32(len(data))), data)
+		if err != nil {
+			t.Fatalf("%v: %v", v.data, err)
+		}
+		ftf := frame.(*FullTextFrame)
+		if ftf.Description() != v.description || ftf.String() != v.text {
+			t.Errorf("%v: incorrect frame, %q %q", v.data, ftf.Description(), ftf.String())
+		}
+	}
+}
+
+func TestPopularimeter(t *testing.T) {
+	for _, version := range []uint8{2, 3, 4} {
+		tag := newTag(&Header{version: version}, nil)
+		tag.SetRating(WindowsMediaPlayerEmail, StarsToRating(4))
+		tag.addFrame(NewPopularimeterFrame(tag.frameId(FramePopularimeter), "user@example.com", 255, 1<<40))
+		b, err := tag.Marshal(version)
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		read, err := Read(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		pf := read.Popularimeter(WindowsMediaPlayerEmail)
+		if pf == nil {
+			t.Fatalf("v2.%v: missing popularimeter", version)
+		}
+		if pf.Rating() != 196 || pf.Stars() != 4 || pf.Counter() != 0 {
+			t.Errorf("v2.%v: incorrect popularimeter, %v", version, pf)
+		}
+		pf = read.Popularimeter("user@example.com")
+		if pf == nil || pf.Stars() != 5 || pf.Counter() != 1<<40 {
+			t.Errorf("v2.%v: incorrect popularimeter, %v", version, pf)
+		}
+	}
+
+	// A counter too large for a uint64 is written back in full
+	data := []byte("user@example.com\x00\xff\x01\xff\xff\xff\xff\xff\xff\xff\xff")
+	frame, err := newPopularimeterFrame(nil, newFrameHeader("POPM", 0, 0, uint32(len(data))), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf := frame.(*PopularimeterFrame)
+	if n := pf.Counter(); n != math.MaxUint64 {
+		t.Errorf("oversized counter did not saturate, %v", n)
+	}
+	if b, _ := pf.encode(3); !bytes.Equal(b, data) {
+		t.Errorf("oversized counter not written as read, %x", b)
+	}
+
+	for stars := 0; stars <= 5; stars++ {
+		if n := RatingToStars(StarsToRating(stars)); n != stars {
+			t.Errorf("%v stars mapped to %v", stars, n)
+		}
+	}
+}
+
+func TestPlayCounter(t *testing.T) {
+	tag := newTag(&Header{version: 3}, nil)
+	tag.IncrementPlayCount()
+	tag.IncrementPlayCount()
+	if n := tag.PlayCount(); n != 2 {
+		t.Errorf("incorrect play count, %v", n)
+	}
+	b, err := tag.Marshal(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte("PCNT\x00\x00\x00\x04\x00\x00\x00\x00\x00\x02")) {
+		t.Errorf("counter not written as four bytes")
+	}
+
+	// A counter too large for a uint64
+	data := []byte{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	frame, err := newPlayCounterFrame(nil, newFrameHeader("PCNT", 0, 0, uint32(len(data))), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcf := frame.(*PlayCounterFrame)
+	if n := pcf.Count(); n != math.MaxUint64 {
+		t.Errorf("oversized counter did not saturate, %v", n)
+	}
+	pcf.Increment()
+	if s := pcf.BigCount().Text(16); s != "20000000000000000" {
+		t.Errorf("incorrect counter after increment, %v", s)
+	}
+}
+
+func TestURLFrames(t *testing.T) {
+	r, err := os.Open("test/v24tagswithalbumimage.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := Read(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if urls := tag.ArtistURLs(); len(urls) != 1 || urls[0] != "OFFICIALARTISTURL23456789012345" {
+		t.Errorf("incorrect artist URLs, %q", urls)
+	}
+	if urls := tag.CommercialURLs(); len(urls) != 1 || urls[0] != "COMMERCIALURL234567890123456789" {
+		t.Errorf("incorrect commercial URLs, %q", urls)
+	}
+	if s := tag.UserURL(""); s != "URL2345678901234567890123456789" {
+		t.Errorf("incorrect user URL, %q", s)
+	}
+
+	for _, version := range []uint8{2, 3, 4} {
+		tag := newTag(&Header{version: version}, nil)
+		tag.AddURL(FrameArtistURL, "http://example.com/artist")
+		tag.SetUserURL("Ünïcode ☃", "http://example.com/user")
+		b, err := tag.Marshal(version)
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		read, err := Read(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		if urls := read.ArtistURLs(); len(urls) != 1 || urls[0] != "http://example.com/artist" {
+			t.Errorf("v2.%v: incorrect artist URLs, %q", version, urls)
+		}
+		if s := read.UserURL("Ünïcode ☃"); s != "http://example.com/user" {
+			t.Errorf("v2.%v: incorrect user URL, %q", version, s)
+		}
+		read.SetUserURL("Ünïcode ☃", "")
+		if n := len(read.FramesByID(FrameUserURL)); n != 0 {
+			t.Errorf("v2.%v: user URL not removed", version)
+		}
+	}
+}
+
+func TestSynchronizedLyrics(t *testing.T) {
+	lrc := "[ar:Someone]\n[offset:+100]\n[00:01.10]First ☃\n[00:05.50][01:02.345]Chorus\n\n[00:03.00]Second\n"
+	entries, err := ParseLRC(strings.NewReader(lrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SyncedText{{"First ☃", 1000}, {"Second", 2900}, {"Chorus", 5400}, {"Chorus", 62245}}
+	if fmt.Sprint(entries) != fmt.Sprint(expected) {
+		t.Fatalf("incorrect entries, %v", entries)
+	}
+
+	for _, version := range []uint8{2, 3, 4} {
+		tag := newTag(&Header{version: version}, nil)
+		tag.AddFrame(NewSynchronizedLyricsFrame(tag.frameId(FrameSynchronizedLyrics), language.MustParseBase("deu"), "Lyrics", entries))
+		b, err := tag.Marshal(version)
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		read, err := Read(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		lyrics := read.SynchronizedLyrics()
+		if len(lyrics) != 1 {
+			t.Fatalf("v2.%v: expected 1 SYLT frame, got %v", version, len(lyrics))
+		}
+		slf := lyrics[0]
+		if slf.Description() != "Lyrics" || slf.Language().String() != "de" || slf.ContentType() != LyricsContentLyrics {
+			t.Errorf("v2.%v: incorrect frame, %q %v %v", version, slf.Description(), slf.Language(), slf.ContentType())
+		}
+		s, err := slf.LRC()
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		if s != "[00:01.00]First ☃\n[00:02.90]Second\n[00:05.40]Chorus\n[01:02.24]Chorus\n" {
+			t.Errorf("v2.%v: incorrect LRC, %q", version, s)
+		}
+	}
+}
+
+func TestGeneralObjects(t *testing.T) {
+	payload := []byte("application/octet-stream\x00Serato\x00\x01\x02")
+	for _, version := range []uint8{2, 3, 4} {
+		tag := newTag(&Header{version: version}, nil)
+		tag.AddFrame(NewGeneralObjectFrame(tag.frameId(FrameGeneralObject), "application/octet-stream", "", "Serato Markers2", payload))
+		tag.AddFrame(NewGeneralObjectFrame(tag.frameId(FrameGeneralObject), "application/pdf", "booklet ☃.pdf", "Booklet", []byte("%PDF")))
+		b, err := tag.Marshal(version)
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		read, err := Read(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		if n := len(read.Objects()); n != 2 {
+			t.Fatalf("v2.%v: expected 2 objects, got %v", version, n)
+		}
+		gof := read.Object("Serato Markers2")
+		if gof == nil || gof.MIMEType() != "application/octet-stream" || !bytes.Equal(gof.Data(), payload) {
+			t.Errorf("v2.%v: incorrect object, %v", version, gof)
+		}
+		gof = read.Object("Booklet")
+		if gof == nil || gof.Filename() != "booklet ☃.pdf" || string(gof.Data()) != "%PDF" {
+			t.Errorf("v2.%v: incorrect object, %v", version, gof)
+		}
+	}
+}
+
+func TestPictureDescription(t *testing.T) {
+	tag := newTag(&Header{version: 3}, nil)
+	pf := &PictureFrame{pictureType: PictureTypeFrontCover, description: "Cover", mime: "image/png", data: []byte("\x89PNG")}
+	pf.header = newFrameHeader("APIC", 0, 0, 0)
+	tag.AddFrame(pf)
+	b, err := tag.Marshal(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, ok := read.Frame(FrameAttachedPicture).(*PictureFrame)
+	if !ok || pf.pictureType != PictureTypeFrontCover || pf.description != "Cover" || string(pf.data) != "\x89PNG" {
+		t.Errorf("incorrect picture, %v", pf)
+	}
+}
+
+func TestPrivateFrames(t *testing.T) {
+	tag := newTag(&Header{version: 3}, nil)
+	tag.AddFrame(NewPrivateFrame("PRIV", OwnerMediaClassPrimaryID, []byte{0xBC, 0x7D, 0x60, 0xD1, 0x23, 0xE3, 0xE2, 0x4B, 0x86, 0xA1, 0x48, 0xA4, 0x2A, 0x28, 0x44, 0x1E}))
+	tag.AddFrame(NewPrivateFrame("PRIV", OwnerPeakValue, []byte{0x10, 0x27, 0, 0}))
+	tag.AddFrame(NewPrivateFrame("PRIV", OwnerProvider, []byte("A\x00M\x00G\x00\x00\x00")))
+	tag.AddFrame(NewPrivateFrame("PRIV", "http://example.com", []byte{1, 2, 3}))
+	b, err := tag.Marshal(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(read.PrivateFrames("")); n != 4 {
+		t.Fatalf("expected 4 private frames, got %v", n)
+	}
+	for _, v := range []struct {
+		owner    string
+		expected interface{}
+	}{
+		{OwnerMediaClassPrimaryID, "{D1607DBC-E323-4BE2-86A1-48A42A28441E}"},
+		{OwnerPeakValue, uint32(10000)},
+		{OwnerProvider, "AMG"},
+		{"http://example.com", []byte{1, 2, 3}},
+	} {
+		frames := read.PrivateFrames(v.owner)
+		if len(frames) != 1 {
+			t.Errorf("%v: expected 1 frame, got %v", v.owner, len(frames))
+			continue
+		}
+		value, err := frames[0].Value()
+		if err != nil {
+			t.Errorf("%v: %v", v.owner, err)
+		}
+		if fmt.Sprint(value) != fmt.Sprint(v.expected) {
+			t.Errorf("%v: incorrect value, %v", v.owner, value)
+		}
+	}
+}
+
+func TestChapters(t *testing.T) {
+	r, err := os.Open("test/v23tagwithchapters.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := Read(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(name string, tag *Tag) {
+		toc := tag.TableOfContents()
+		if toc == nil || toc.ElementID() != "toc1" || !toc.Ordered() {
+			t.Fatalf("%v: incorrect table of contents, %v", name, toc)
+		}
+		chapters := tag.Chapters()
+		expected := []struct {
+			id         string
+			title      string
+			start, end time.Duration
+		}{
+			{"ch1", "start", 0, 5 * time.Second},
+			{"ch2", "5 seconds", 5 * time.Second, 10 * time.Second},
+			{"ch3", "10 seconds", 10 * time.Second, 15 * time.Second},
+		}
+		if len(chapters) != len(expected) {
+			t.Fatalf("%v: expected %v chapters, got %v", name, len(expected), len(chapters))
+		}
+		for i, e := range expected {
+			c := chapters[i]
+			if c.ElementID() != e.id || c.Title() != e.title || c.StartTime() != e.start || c.EndTime() != e.end {
+				t.Errorf("%v: incorrect chapter, %v", name, c)
+			}
+			if _, ok := c.StartOffset(); ok {
+				t.Errorf("%v: unexpected start offset in %v", name, c)
+			}
+		}
+	}
+	check("v2.3", tag)
+
+	if _, err := tag.ConvertTo(4); err != nil {
+		t.Fatal(err)
+	}
+	b, err := tag.Marshal(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("v2.4", read)
+}
+
+func TestChapterAuthoring(t *testing.T) {
+	entries := []ChapterEntry{
+		{Start: 0, Title: "Intro", URL: "http://example.com/intro"},
+		{Start: 90 * time.Second, Title: "Talk; part=1", Image: []byte("http://example.com/talk.png"), ImageMIME: PictureLinkMIME},
+		{Start: time.Hour + 1500*time.Millisecond, End: time.Hour + time.Minute, Title: "Outro"},
+	}
+	tag := newTag(&Header{version: 3}, nil)
+	tag.SetTitle("Episode")
+	if err := tag.SetChapters(entries); err != nil {
+		t.Fatal(err)
+	}
+	b, err := tag.Marshal(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	toc := read.TableOfContents()
+	if toc == nil || !toc.Ordered() || len(toc.ChildElementIDs()) != 3 {
+		t.Fatalf("incorrect table of contents, %v", toc)
+	}
+	got := read.ChapterEntries()
+	if len(got) != 3 {
+		t.Fatalf("expected 3 chapters, got %v", len(got))
+	}
+	if got[0].End != 90*time.Second || got[0].URL != "http://example.com/intro" {
+		t.Errorf("incorrect first chapter, %+v", got[0])
+	}
+	if got[1].End != entries[2].Start || got[1].ImageMIME != PictureLinkMIME || string(got[1].Image) != "http://example.com/talk.png" {
+		t.Errorf("incorrect second chapter, %+v", got[1])
+	}
+
+	j, err := FormatPodloveJSON(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(j), `"start": "01:00:01.500"`) {
+		t.Errorf("incorrect Podlove JSON, %s", j)
+	}
+	podlove, err := ParsePodloveJSON(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(podlove) != 3 || podlove[2].Start != entries[2].Start || podlove[1].Title != entries[1].Title || string(podlove[1].Image) != "http://example.com/talk.png" {
+		t.Errorf("incorrect Podlove chapters, %+v", podlove)
+	}
+
+	ff := FormatFFMetadata(got)
+	if !strings.Contains(ff, `title=Talk\; part\=1`+"\n") {
+		t.Errorf("incorrect ffmetadata, %q", ff)
+	}
+	parsed, err := ParseFFMetadata(strings.NewReader(ff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 3 {
+		t.Fatalf("expected 3 ffmetadata chapters, got %v", len(parsed))
+	}
+	for i := range parsed {
+		if parsed[i].Start != got[i].Start || parsed[i].End != got[i].End || parsed[i].Title != got[i].Title {
+			t.Errorf("incorrect ffmetadata chapter, %+v", parsed[i])
+		}
+	}
+	parsed, err = ParseFFMetadata(strings.NewReader(";FFMETADATA1\ntitle=Show\n[CHAPTER]\nTIMEBASE=1/44100\nSTART=44100\nEND=88200\ntitle=Two\\\nlines\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 1 || parsed[0].Start != time.Second || parsed[0].End != 2*time.Second || parsed[0].Title != "Two\nlines" {
+		t.Errorf("incorrect ffmetadata chapter, %+v", parsed)
+	}
+}
+
+func TestCredits(t *testing.T) {
+	tag := newTag(&Header{version: 3}, nil)
+	tag.AddFrame(NewInvolvedPeopleFrame("IPLS", []InvolvedPerson{{"producer", "Alice"}, {"engineer", "Bob"}}))
+	b, err := tag.Marshal(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []InvolvedPerson{{"producer", "Alice"}, {"engineer", "Bob"}}
+	if credits := read.Credits(); fmt.Sprint(credits) != fmt.Sprint(expected) {
+		t.Errorf("incorrect v2.3 credits, %v", credits)
+	}
+
+	if _, err := read.ConvertTo(4); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := read.Frame(FrameInvolvedPeople).(*InvolvedPeopleFrame); !ok {
+		t.Fatalf("IPLS not converted to TIPL")
+	}
+	read.AddFrame(NewInvolvedPeopleFrame("TMCL", []InvolvedPerson{{"guitar", "Carol"}, {"producer", "Alice"}}))
+	b, err = read.Marshal(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err = Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = append(expected, InvolvedPerson{"guitar", "Carol"})
+	if credits := read.Credits(); fmt.Sprint(credits) != fmt.Sprint(expected) {
+		t.Errorf("incorrect v2.4 credits, %v", credits)
+	}
+
+	if _, err := read.ConvertTo(3); err != nil {
+		t.Fatal(err)
+	}
+	frames := read.FramesByID(FrameInvolvedPeopleList)
+	if len(frames) != 1 || len(frames[0].(*InvolvedPeopleFrame).People()) != 4 {
+		t.Errorf("TIPL and TMCL not merged into IPLS, %v", frames)
+	}
+}
+
+func TestMultipleValues(t *testing.T) {
+	for _, v := range []struct {
+		version uint8
+		encoded string
+	}{
+		{3, "TPE1\x00\x00\x00\x0a\x00\x00\x00Alice/Bob"},
+		{4, "TPE1\x00\x00\x00\x0a\x00\x00\x00Alice\x00Bob"},
+	} {
+		tag := newTag(&Header{version: v.version}, nil)
+		tag.SetArtists("Alice", "Bob")
+		tag.SetGenres("Rock", "Jazz")
+		b, err := tag.Marshal(v.version)
+		if err != nil {
+			t.Fatalf("v2.%v: %v", v.version, err)
+		}
+		if !bytes.Contains(b, []byte(v.encoded)) {
+			t.Errorf("v2.%v: artists not written as %q", v.version, v.encoded)
+		}
+		read, err := Read(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("v2.%v: %v", v.version, err)
+		}
+		if artists := read.Artists(); fmt.Sprint(artists) != "[Alice Bob]" {
+			t.Errorf("v2.%v: incorrect artists, %q", v.version, artists)
+		}
+		if s := read.Artist(); s != "Alice/Bob" {
+			t.Errorf("v2.%v: incorrect artist, %q", v.version, s)
+		}
+		if genres := read.Genres(); v.version == 4 && fmt.Sprint(genres) != "[Rock Jazz]" {
+			t.Errorf("v2.%v: incorrect genres, %q", v.version, genres)
+		}
+	}
+
+	// Before v2.4 text after the first terminator is padding, not values
+	for _, v := range []struct {
+		version  uint8
+		data     string
+		expected string
+	}{
+		{3, "\x00Title\x00\x00\x00", "[Title]"},
+		{3, "\x00Title\x00junk", "[Title]"},
+		{4, "\x00Title\x00\x00\x00", "[Title]"},
+		{4, "\x00A\x00B\x00", "[A B]"},
+	} {
+		tag := newTag(&Header{version: v.version}, nil)
+		frame, err := newTextFrame(tag, newFrameHeader("TIT2", 0, 0, uint32(len(v.data))), []byte(v.data))
+		if err != nil {
+			t.Fatalf("v2.%v %q: %v", v.version, v.data, err)
+		}
+		if values := frame.(*TextFrame).Values(); fmt.Sprint(values) != v.expected {
+			t.Errorf("v2.%v %q: incorrect values, %q", v.version, v.data, values)
+		}
+	}
+
+	for value, expected := range map[string]string{
+		"(17)":             "[Rock]",
+		"(17)Rock":         "[Rock]",
+		"(8)(RX)Acid Jazz": "[Jazz Remix Acid Jazz]",
+		"((Not a ref)":     "[(Not a ref)]",
+		"32":               "[Classical]",
+		"CR":               "[Cover]",
+		"Shoegaze":         "[Shoegaze]",
+	} {
+		if genres := parseGenres(value); fmt.Sprint(genres) != expected {
+			t.Errorf("%q: incorrect genres, %q", value, genres)
+		}
+	}
+}
+
+func TestReplayGain(t *testing.T) {
+	tag := newTag(&Header{version: 4}, nil)
+	tag.AddFrame(NewRelativeVolumeAdjustment2Frame("RVA2", "track",
+		VolumeAdjustment{Channel: ChannelMaster, Adjustment: -6.5, Peak: 0.5}))
+	tag.AddFrame(NewRelativeVolumeAdjustment2Frame("RVA2", "album",
+		VolumeAdjustment{Channel: ChannelMaster, Adjustment: -7.25}))
+	b, err := tag.Marshal(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte("track\x00\x01\xf3\x00\x10\x40\x00")) {
+		t.Errorf("track adjustment not written")
+	}
+	read, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	track, album := read.ReplayGain()
+	if track == nil || *track != (ReplayGain{-6.5, 0.5}) {
+		t.Errorf("incorrect track gain from RVA2, %+v", track)
+	}
+	if album == nil || *album != (ReplayGain{-7.25, 0}) {
+		t.Errorf("incorrect album gain from RVA2, %+v", album)
+	}
+
+	// TXXX frames are preferred over RVA2
+	read.AddFrame(NewDescribedFrame("TXXX", "replaygain_track_gain", "-3.20 dB"))
+	read.AddFrame(NewDescribedFrame("TXXX", "REPLAYGAIN_TRACK_PEAK", "0.988312"))
+	if track, _ = read.ReplayGain(); track == nil || *track != (ReplayGain{-3.2, 0.988312}) {
+		t.Errorf("incorrect track gain from TXXX, %+v", track)
+	}
+
+	data := []byte{0x3F, 0x80, 0x00, 0x00, 0x2E, 0x17, 0x48, 0x0C}
+	frame, err := newReplayGainFrame(nil, newFrameHeader("RGAD", 0, 0, uint32(len(data))), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag = newTag(&Header{version: 3}, nil)
+	tag.AddFrame(frame)
+	track, album = tag.ReplayGain()
+	if track == nil || *track != (ReplayGain{-2.3, 1}) {
+		t.Errorf("incorrect track gain from RGAD, %+v", track)
+	}
+	if album == nil || *album != (ReplayGain{1.2, 0}) {
+		t.Errorf("incorrect album gain from RGAD, %+v", album)
+	}
+	if b, _ := frame.(*ReplayGainFrame).encode(3); !bytes.Equal(b, data) {
+		t.Errorf("RGAD not written as read, %x", b)
+	}
+
+	data = []byte{0x01, 0x10, 0x01, 0x00, 0x02, 0x00, 0x7F, 0xFF, 0x7F, 0xFF}
+	frame, err = newRelativeVolumeAdjustmentFrame(nil, newFrameHeader("RVAD", 0, 0, uint32(len(data))), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rvf := frame.(*RelativeVolumeAdjustmentFrame)
+	if s := fmt.Sprint(rvf.Channels()); s != "[{2 256 32767} {3 -512 32767}]" {
+		t.Errorf("incorrect RVAD channels, %v", s)
+	}
+	if b, _ := rvf.encode(3); !bytes.Equal(b, data) {
+		t.Errorf("RVAD not written as read, %x", b)
+	}
+}
+
+func TestTimingCodes(t *testing.T) {
+	for _, version := range []uint8{2, 3, 4} {
+		tag := newTag(&Header{version: version}, nil)
+		tag.AddFrame(NewEventTimingCodesFrame(FrameEventTimingCodes.ID(version), TimestampMilliseconds,
+			TimedEvent{Type: EventIntroStart, Timestamp: 0},
+			TimedEvent{Type: EventMainPartStart, Timestamp: 15000},
+			TimedEvent{Type: EventOutroStart, Timestamp: 180000}))
+		tag.AddFrame(NewSynchronizedTempoCodesFrame(FrameSynchronizedTempoCodes.ID(version), TimestampMilliseconds,
+			TempoChange{128, 0},
+			TempoChange{300, 60000}))
+		b, err := tag.Marshal(version)
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		read, err := Read(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("v2.%v: %v", version, err)
+		}
+		etf := read.EventTimingCodes()
+		if etf == nil {
+			t.Fatalf("v2.%v: no event timing codes", version)
+		}
+		if event, ok := etf.Event(EventMainPartStart); !ok || event.Timestamp != 15000 {
+			t.Errorf("v2.%v: incorrect main part start, %v", version, event)
+		}
+		if events := etf.Events(); len(events) != 3 || etf.TimestampFormat() != TimestampMilliseconds {
+			t.Errorf("v2.%v: incorrect events, %v", version, events)
+		}
+		stf := read.SynchronizedTempoCodes()
+		if stf == nil {
+			t.Fatalf("v2.%v: no tempo codes", version)
+		}
+		if changes := stf.Changes(); fmt.Sprint(changes) != "[{128 0} {300 60000}]" {
+			t.Errorf("v2.%v: incorrect tempo changes, %v", version, changes)
+		}
+	}
+
+	// Extended event types keep their EventMore prefixes
+	data := []byte{0x02, 0xFF, 0xFF, 0x01, 0x00, 0x00, 0x03, 0xE8, 0x02, 0x00, 0x00, 0x07, 0xD0}
+	frame, err := newEventTimingCodesFrame(nil, newFrameHeader("ETCO", 0, 0, uint32(len(data))), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	etf := frame.(*EventTimingCodesFrame)
+	if events := etf.Events(); fmt.Sprint(events) != "[{1 1000 2} {2 2000 0}]" {
+		t.Errorf("incorrect extended events, %v", events)
+	}
+	if b, _ := etf.encode(3); !bytes.Equal(b, data) {
+		t.Errorf("extended events not written as read, %x", b)
+	}
+
+	data = []byte{0x02, 0xFF, 0x2D, 0x00, 0x00, 0x03, 0xE8}
+	frame, err = newSynchronizedTempoCodesFrame(nil, newFrameHeader("SYTC", 0, 0, uint32(len(data))), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := frame.(*SynchronizedTempoCodesFrame).encode(3); !bytes.Equal(b, data) {
+		t.Errorf("tempo codes not written as read, %x", b)
+	}
+}
+
+func TestEncryptedFrames(t *testing.T) {
+	xor := func(data []byte, key byte) []byte {
+		b := make([]byte, len(data))
+		for i := range data {
+			b[i] = data[i] ^ key
+		}
+		return b
+	}
+
+	tag := newTag(&Header{version: 4}, nil)
+	tag.AddFrame(NewRegistrationFrame("ENCR", "http://example.com/xor", 0x80, []byte{0x5A}))
+	tag.AddFrame(NewRegistrationFrame("GRID", "http://example.com/group", 0x81, nil))
+	artist := NewTextFrame("TPE1", "Artist")
+	artist.SetGroupID(0x81)
+	tag.AddFrame(artist)
+	b, err := tag.Marshal(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Add an encrypted title to the frames and fix up the tag size
+	title := append([]byte{0x80}, xor([]byte("\x03Title"), 0x5A)...)
+	frame := append([]byte("TIT2"), safe(uint32(len(title)))...)
+	frame = append(append(frame, 0x00, formatEncryptedV24), title...)
+	b = append(b[:headerSize], append(frame, b[headerSize:]...)...)
+	copy(b[6:headerSize], safe(uint32(len(b))-headerSize))
+
+	read, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ef, ok := read.Frame(FrameTitle).(*EncryptedFrame); !ok {
+		t.Fatalf("encrypted frame not kept as read, %T", read.Frame(FrameTitle))
+	} else if method, ok := ef.EncryptionMethod(); !ok || method != 0x80 {
+		t.Errorf("incorrect encryption method, %#x", method)
+	}
+	if frames := read.GroupFrames(0x81); len(frames) != 1 || frames[0].String() != "Artist" {
+		t.Errorf("incorrect group frames, %v", frames)
+	}
+	if rf := read.GroupRegistration(0x81); rf == nil || rf.Owner() != "http://example.com/group" {
+		t.Errorf("incorrect group registration, %v", rf)
+	}
+
+	// Encrypted frames are written back unchanged
+	rewritten, err := read.Marshal(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(rewritten, frame) {
+		t.Errorf("encrypted frame not written as read")
+	}
+
+	err = read.Decrypt(DecrypterFunc(func(method byte, registration *RegistrationFrame, data []byte) ([]byte, error) {
+		if registration == nil || len(registration.Data()) != 1 {
+			return nil, errors.New("unknown encryption method")
+		}
+		return xor(data, registration.Data()[0]), nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title := read.Title(); title != "Title" {
+		t.Errorf("incorrect decrypted title, %q", title)
+	}
+	if _, ok := read.Frame(FrameTitle).EncryptionMethod(); ok {
+		t.Errorf("decrypted frame still marked as encrypted")
+	}
+}
+
+func TestCommercialFrames(t *testing.T) {
+	validUntil := time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC)
+	purchased := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	english, french := language.MustParseBase("en"), language.MustParseBase("fr")
+
+	tag := newTag(&Header{version: 3}, nil)
+	cf := NewCommercialFrame("COMR", []Price{{"USD", "9.99"}, {"EUR", "8.99"}}, validUntil,
+		"http://shop.example.com", ReceivedAsFileOverInternet, "Example Records", "Digital album")
+	cf.SetSellerLogo("image/png", []byte{0x89, 'P', 'N', 'G'})
+	tag.AddFrame(cf)
+	tag.AddFrame(NewOwnershipFrame("OWNE", Price{"GBP", "7.50"}, purchased, "Sèller"))
+	tag.AddFrame(NewTermsOfUseFrame("USER", english, "Personal use only"))
+	tag.AddFrame(NewTermsOfUseFrame("USER", french, "Usage personnel uniquement"))
+
+	b, err := tag.Marshal(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte("\x00USD9.99/EUR8.99\x0020301231http://shop.example.com\x00\x03")) {
+		t.Errorf("commercial frame not written as expected")
+	}
+	read, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frames := read.Commercial()
+	if len(frames) != 1 {
+		t.Fatalf("incorrect number of commercial frames, %v", len(frames))
+	}
+	cf = frames[0]
+	if prices := cf.Prices(); fmt.Sprint(prices) != "[USD9.99 EUR8.99]" || prices[1].Currency != "EUR" {
+		t.Errorf("incorrect prices, %v", prices)
+	}
+	if !cf.ValidUntil().Equal(validUntil) || cf.ContactURL() != "http://shop.example.com" ||
+		cf.ReceivedAs() != ReceivedAsFileOverInternet || cf.Seller() != "Example Records" ||
+		cf.Description() != "Digital album" {
+		t.Errorf("incorrect commercial frame, %+v", cf)
+	}
+	if mime, logo := cf.SellerLogo(); mime != "image/png" || string(logo) != "\x89PNG" {
+		t.Errorf("incorrect seller logo, %v %x", mime, logo)
+	}
+
+	of := read.Ownership()
+	if of == nil {
+		t.Fatal("no ownership frame")
+	}
+	if of.Price() != (Price{"GBP", "7.50"}) || !of.Purchased().Equal(purchased) || of.Seller() != "Sèller" {
+		t.Errorf("incorrect ownership frame, %v %v %v", of.Price(), of.Purchased(), of.Seller())
+	}
+
+	if tf := read.TermsOfUse(french); tf == nil || tf.String() != "Usage personnel uniquement" {
+		t.Errorf("incorrect French terms of use, %v", tf)
+	}
+	if tf := read.TermsOfUse(language.MustParseBase("de")); tf == nil || tf.Language() != english {
+		t.Errorf("terms of use did not fall back to the first frame, %v", tf)
+	}
+}
+
+func TestMusicCDIdentifier(t *testing.T) {
+	toc := &CDTOC{FirstTrack: 1, LastTrack: 12, LeadOut: 267257 - 150}
+	for _, offset := range []uint32{150, 22767, 41887, 58317, 72102, 91375, 104652, 115380, 132165, 143932, 159870, 174597} {
+		toc.Offsets = append(toc.Offsets, offset-150)
+	}
+	if id := toc.FreeDBID(); id != "a70de90c" {
+		t.Errorf("incorrect freedb ID, %v", id)
+	}
+	if id := toc.MusicBrainzID(); id != "I5l9cCSFccLKFEKS.7wqSZAorPU-" {
+		t.Errorf("incorrect MusicBrainz ID, %v", id)
+	}
+
+	tag := newTag(&Header{version: 3}, nil)
+	tag.AddFrame(NewMCDIFrame("MCDI", toc))
+	b, err := tag.Marshal(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mf := read.MusicCDIdentifier()
+	if mf == nil {
+		t.Fatal("no music CD identifier")
+	}
+	if len(mf.Bytes()) != 4+8*13 {
+		t.Errorf("incorrect TOC length, %v", len(mf.Bytes()))
+	}
+	parsed, err := mf.TOC()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(parsed) != fmt.Sprint(toc) {
+		t.Errorf("TOC not read as written, %v", parsed)
+	}
+
+	if _, err := ParseCDTOC(mf.Bytes()[:20]); err == nil {
+		t.Errorf("truncated TOC parsed without error")
+	}
+}
+
+func TestMalformedFrames(t *testing.T) {
+	for _, v := range []struct {
+		id    string
+		maker func(*Tag, *frameHeader, []byte) (Frame, error)
+		data  string
+	}{
+		// A UTF-16 description that ends in the middle of a character
+		{"GEOB", newGeneralObjectFrame, "0201b203000303a1188a0000fc00"},
+		{"WXXX", newUserURLFrame, "0246"},
+		{"SYLT", newSynchronizedLyricsFrame, "01656e670201fffe41"},
+		{"SYLT", newSynchronizedLyricsFrame, "01656e6702010000fffe414243444546474849"},
+		{"COMR", newCommercialFrame, "015553443100323033303132333175726c0003fffe41"},
+		{"COMR", newCommercialFrame, "015553443100323033303132333175726c00030000fffe41"},
+		{"OWNE", newOwnershipFrame, "0155534431003230323430323239fffe41"},
+		{"USER", newTermsOfUseFrame, "01656e67fffe41"},
+		// Bodies too short for their fixed fields
+		{"COMM", newFullTextFrame, ""},
+		{"COMM", newFullTextFrame, "03"},
+		{"COMM", newFullTextFrame, "03000000"},
+		{"USLT", newFullTextFrame, "03"},
+		{"USLT", newFullTextFrame, "03000000"},
+		{"COM", newFullTextFrame, ""},
+		{"TXXX", newDescribedFrame, ""},
+		{"TXXX", newDescribedFrame, "03"},
+		{"APIC", newPictureFrame, ""},
+		{"APIC", newPictureFrame, "03"},
+		{"APIC", newPictureFrame, "0000ff"},
+		// Volume fields wider than 64 bits
+		{"RVA2", newRelativeVolumeAdjustment2Frame, "7472616e6b0001000048" + strings.Repeat("ff", 9)},
+		{"RVAD", newRelativeVolumeAdjustmentFrame, "0348" + strings.Repeat("01", 36)},
+		{"RVAD", newRelativeVolumeAdjustmentFrame, "0340" + strings.Repeat("ff", 8) + strings.Repeat("00", 24)},
+	} {
+		data, err := hex.DecodeString(v.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag := newTag(&Header{version: 3}, nil)
+		if _, err = v.maker(tag, newFrameHeader(v.id, 0, 0, uint32(len(data))), data); err == nil {
+			t.Errorf("%v %v: parsed without error", v.id, v.data)
+		}
+	}
+}
+
+func TestWriteV1Tag(t *testing.T) {
+	r, err := os.Open("test/v1tag.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := Read(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.Header != nil {
+		t.Fatalf("expected a tag without a header")
+	}
+	title, artist := tag.Title(), tag.Artist()
+
+	var b bytes.Buffer
+	_, err = tag.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Header == nil || read.Header.Version() != 4 {
+		t.Fatalf("ID3v1 tag not written as v2.4")
+	}
+	if read.Frame(FrameTitle) == nil || read.Frame(FrameTitle).Id() != "TIT2" {
+		t.Errorf("title not written with a v2.4 frame ID")
+	}
+	if read.Title() != title || read.Artist() != artist {
+		t.Errorf("incorrect tag after writing, %q %q", read.Title(), read.Artist())
+	}
+	// Writing converts a copy, not the tag itself
+	if tag.Header != nil {
+		t.Errorf("tag was given a header by writing it")
+	}
+	if id := tag.Frame(FrameTitle).Id(); id != "TT2" {
+		t.Errorf("tag frame IDs changed by writing it, %v", id)
+	}
+}
@@ -0,0 +1,243 @@
+package id3
+
+// FrameKind identifies a frame independently of the ID3v2 version it is
+// stored in. Its value is the frame's ID in the newest version that defines
+// it, so frame IDs from any version can also be used where a FrameKind is
+// expected.
+type FrameKind string
+
+const (
+	FrameAudioEncryption                 FrameKind = "AENC"
+	FrameAttachedPicture                 FrameKind = "APIC"
+	FrameChapter                         FrameKind = "CHAP"
+	FrameComments                        FrameKind = "COMM"
+	FrameCommercial                      FrameKind = "COMR"
+	FrameEncryptedMeta                   FrameKind = "CRM"
+	FrameTableOfContents                 FrameKind = "CTOC"
+	FrameEncryptionMethodRegistration    FrameKind = "ENCR"
+	FrameEqualization                    FrameKind = "EQUA"
+	FrameEventTimingCodes                FrameKind = "ETCO"
+	FrameGeneralObject                   FrameKind = "GEOB"
+	FrameGroupIdentificationRegistration FrameKind = "GRID"
+	FrameInvolvedPeopleList              FrameKind = "IPLS"
+	FrameLinkedInformation               FrameKind = "LINK"
+	FrameMusicCDIdentifier               FrameKind = "MCDI"
+	FrameMPEGLocationLookupTable         FrameKind = "MLLT"
+	FrameOwnership                       FrameKind = "OWNE"
+	FramePrivate                         FrameKind = "PRIV"
+	FramePlayCounter                     FrameKind = "PCNT"
+	FramePopularimeter                   FrameKind = "POPM"
+	FramePositionSynchronisation         FrameKind = "POSS"
+	FrameRecommendedBufferSize           FrameKind = "RBUF"
+	FrameReplayGain                      FrameKind = "RGAD"
+	FrameRelativeVolumeAdjustment        FrameKind = "RVAD"
+	FrameRelativeVolumeAdjustment2       FrameKind = "RVA2"
+	FrameReverb                          FrameKind = "RVRB"
+	FrameSeek                            FrameKind = "SEEK"
+	FrameSynchronizedLyrics              FrameKind = "SYLT"
+	FrameSynchronizedTempoCodes          FrameKind = "SYTC"
+	FrameAlbum                           FrameKind = "TALB"
+	FrameBPM                             FrameKind = "TBPM"
+	FrameCompilation                     FrameKind = "TCMP"
+	FrameComposer                        FrameKind = "TCOM"
+	FrameGenre                           FrameKind = "TCON"
+	FrameCopyright                       FrameKind = "TCOP"
+	FrameDate                            FrameKind = "TDAT"
+	FrameEncodingTime                    FrameKind = "TDEN"
+	FramePlaylistDelay                   FrameKind = "TDLY"
+	FrameOriginalReleaseTime             FrameKind = "TDOR"
+	FrameRecordingTime                   FrameKind = "TDRC"
+	FrameReleaseTime                     FrameKind = "TDRL"
+	FrameTaggingTime                     FrameKind = "TDTG"
+	FrameEncodedBy                       FrameKind = "TENC"
+	FrameLyricist                        FrameKind = "TEXT"
+	FrameFileType                        FrameKind = "TFLT"
+	FrameTime                            FrameKind = "TIME"
+	FrameInvolvedPeople                  FrameKind = "TIPL"
+	FrameContentGroup                    FrameKind = "TIT1"
+	FrameTitle                           FrameKind = "TIT2"
+	FrameSubtitle                        FrameKind = "TIT3"
+	FrameInitialKey                      FrameKind = "TKEY"
+	FrameLanguage                        FrameKind = "TLAN"
+	FrameLength                          FrameKind = "TLEN"
+	FrameMusicianCredits                 FrameKind = "TMCL"
+	FrameMediaType                       FrameKind = "TMED"
+	FrameMood                            FrameKind = "TMOO"
+	FrameOriginalAlbum                   FrameKind = "TOAL"
+	FrameOriginalFilename                FrameKind = "TOFN"
+	FrameOriginalLyricist                FrameKind = "TOLY"
+	FrameOriginalArtist                  FrameKind = "TOPE"
+	FrameOriginalReleaseYear             FrameKind = "TORY"
+	FrameFileOwner                       FrameKind = "TOWN"
+	FrameArtist                          FrameKind = "TPE1"
+	FrameBand                            FrameKind = "TPE2"
+	FrameConductor                       FrameKind = "TPE3"
+	FrameRemixer                         FrameKind = "TPE4"
+	FramePartOfSet                       FrameKind = "TPOS"
+	FramePublisher                       FrameKind = "TPUB"
+	FrameTrackNumber                     FrameKind = "TRCK"
+	FrameRecordingDates                  FrameKind = "TRDA"
+	FrameRadioStationName                FrameKind = "TRSN"
+	FrameRadioStationOwner               FrameKind = "TRSO"
+	FrameSize                            FrameKind = "TSIZ"
+	FrameAlbumArtistSortOrder            FrameKind = "TSO2"
+	FrameAlbumSortOrder                  FrameKind = "TSOA"
+	FramePerformerSortOrder              FrameKind = "TSOP"
+	FrameISRC                            FrameKind = "TSRC"
+	FrameEncodingSettings                FrameKind = "TSSE"
+	FrameUserText                        FrameKind = "TXXX"
+	FrameYear                            FrameKind = "TYER"
+	FrameUniqueFileID                    FrameKind = "UFID"
+	FrameTermsOfUse                      FrameKind = "USER"
+	FrameUnsynchronizedLyrics            FrameKind = "USLT"
+	FrameCommercialURL                   FrameKind = "WCOM"
+	FrameCopyrightURL                    FrameKind = "WCOP"
+	FrameAudioFileURL                    FrameKind = "WOAF"
+	FrameArtistURL                       FrameKind = "WOAR"
+	FrameAudioSourceURL                  FrameKind = "WOAS"
+	FrameRadioStationURL                 FrameKind = "WORS"
+	FramePaymentURL                      FrameKind = "WPAY"
+	FramePublisherURL                    FrameKind = "WPUB"
+	FrameUserURL                         FrameKind = "WXXX"
+)
+
+type frameKindIds struct {
+	kind FrameKind
+	ids  [3]string
+}
+
+// frameKinds maps each kind to its frame ID in v2.2, v2.3 and v2.4; an empty ID
+// means the frame cannot be represented in that version.
+var frameKinds = []frameKindIds{
+	{FrameAudioEncryption, [3]string{"CRA", "AENC", "AENC"}},
+	{FrameAttachedPicture, [3]string{"PIC", "APIC", "APIC"}},
+	{FrameChapter, [3]string{"", "CHAP", "CHAP"}},
+	{FrameComments, [3]string{"COM", "COMM", "COMM"}},
+	{FrameCommercial, [3]string{"", "COMR", "COMR"}},
+	{FrameEncryptedMeta, [3]string{"CRM", "", ""}},
+	{FrameTableOfContents, [3]string{"", "CTOC", "CTOC"}},
+	{FrameEncryptionMethodRegistration, [3]string{"", "ENCR", "ENCR"}},
+	{FrameEqualization, [3]string{"EQU", "EQUA", ""}},
+	{FrameEventTimingCodes, [3]string{"ETC", "ETCO", "ETCO"}},
+	{FrameGeneralObject, [3]string{"GEO", "GEOB", "GEOB"}},
+	{FrameGroupIdentificationRegistration, [3]string{"", "GRID", "GRID"}},
+	{FrameInvolvedPeopleList, [3]string{"IPL", "IPLS", ""}},
+	{FrameLinkedInformation, [3]string{"LNK", "LINK", "LINK"}},
+	{FrameMusicCDIdentifier, [3]string{"MCI", "MCDI", "MCDI"}},
+	{FrameMPEGLocationLookupTable, [3]string{"MLL", "MLLT", "MLLT"}},
+	{FrameOwnership, [3]string{"", "OWNE", "OWNE"}},
+	{FramePrivate, [3]string{"", "PRIV", "PRIV"}},
+	{FramePlayCounter, [3]string{"CNT", "PCNT", "PCNT"}},
+	{FramePopularimeter, [3]string{"POP", "POPM", "POPM"}},
+	{FramePositionSynchronisation, [3]string{"", "POSS", "POSS"}},
+	{FrameRecommendedBufferSize, [3]string{"BUF", "RBUF", "RBUF"}},
+	{FrameReplayGain, [3]string{"", "RGAD", "RGAD"}},
+	{FrameRelativeVolumeAdjustment, [3]string{"RVA", "RVAD", ""}},
+	{FrameRelativeVolumeAdjustment2, [3]string{"", "", "RVA2"}},
+	{FrameReverb, [3]string{"REV", "RVRB", "RVRB"}},
+	{FrameSeek, [3]string{"", "", "SEEK"}},
+	{FrameSynchronizedLyrics, [3]string{"SLT", "SYLT", "SYLT"}},
+	{FrameSynchronizedTempoCodes, [3]string{"STC", "SYTC", "SYTC"}},
+	{FrameAlbum, [3]string{"TAL", "TALB", "TALB"}},
+	{FrameBPM, [3]string{"TBP", "TBPM", "TBPM"}},
+	{FrameCompilation, [3]string{"", "TCMP", "TCMP"}},
+	{FrameComposer, [3]string{"TCM", "TCOM", "TCOM"}},
+	{FrameGenre, [3]string{"TCO", "TCON", "TCON"}},
+	{FrameCopyright, [3]string{"TCR", "TCOP", "TCOP"}},
+	{FrameDate, [3]string{"TDA", "TDAT", ""}},
+	{FrameEncodingTime, [3]string{"", "", "TDEN"}},
+	{FramePlaylistDelay, [3]string{"TDY", "TDLY", "TDLY"}},
+	{FrameOriginalReleaseTime, [3]string{"", "", "TDOR"}},
+	{FrameRecordingTime, [3]string{"", "", "TDRC"}},
+	{FrameReleaseTime, [3]string{"", "", "TDRL"}},
+	{FrameTaggingTime, [3]string{"", "", "TDTG"}},
+	{FrameEncodedBy, [3]string{"TEN", "TENC", "TENC"}},
+	{FrameLyricist, [3]string{"TXT", "TEXT", "TEXT"}},
+	{FrameFileType, [3]string{"TFT", "TFLT", "TFLT"}},
+	{FrameTime, [3]string{"TIM", "TIME", ""}},
+	{FrameInvolvedPeople, [3]string{"", "", "TIPL"}},
+	{FrameContentGroup, [3]string{"TT1", "TIT1", "TIT1"}},
+	{FrameTitle, [3]string{"TT2", "TIT2", "TIT2"}},
+	{FrameSubtitle, [3]string{"TT3", "TIT3", "TIT3"}},
+	{FrameInitialKey, [3]string{"TKE", "TKEY", "TKEY"}},
+	{FrameLanguage, [3]string{"TLA", "TLAN", "TLAN"}},
+	{FrameLength, [3]string{"TLE", "TLEN", "TLEN"}},
+	{FrameMusicianCredits, [3]string{"", "", "TMCL"}},
+	{FrameMediaType, [3]string{"TMT", "TMED", "TMED"}},
+	{FrameMood, [3]string{"", "", "TMOO"}},
+	{FrameOriginalAlbum, [3]string{"TOT", "TOAL", "TOAL"}},
+	{FrameOriginalFilename, [3]string{"TOF", "TOFN", "TOFN"}},
+	{FrameOriginalLyricist, [3]string{"TOL", "TOLY", "TOLY"}},
+	{FrameOriginalArtist, [3]string{"TOA", "TOPE", "TOPE"}},
+	{FrameOriginalReleaseYear, [3]string{"TOR", "TORY", ""}},
+	{FrameFileOwner, [3]string{"", "TOWN", "TOWN"}},
+	{FrameArtist, [3]string{"TP1", "TPE1", "TPE1"}},
+	{FrameBand, [3]string{"TP2", "TPE2", "TPE2"}},
+	{FrameConductor, [3]string{"TP3", "TPE3", "TPE3"}},
+	{FrameRemixer, [3]string{"TP4", "TPE4", "TPE4"}},
+	{FramePartOfSet, [3]string{"TPA", "TPOS", "TPOS"}},
+	{FramePublisher, [3]string{"TPB", "TPUB", "TPUB"}},
+	{FrameTrackNumber, [3]string{"TRK", "TRCK", "TRCK"}},
+	{FrameRecordingDates, [3]string{"TRD", "TRDA", ""}},
+	{FrameRadioStationName, [3]string{"", "TRSN", "TRSN"}},
+	{FrameRadioStationOwner, [3]string{"", "TRSO", "TRSO"}},
+	{FrameSize, [3]string{"TSI", "TSIZ", ""}},
+	{FrameAlbumArtistSortOrder, [3]string{"", "TSO2", "TSO2"}},
+	{FrameAlbumSortOrder, [3]string{"", "TSOA", "TSOA"}},
+	{FramePerformerSortOrder, [3]string{"", "TSOP", "TSOP"}},
+	{FrameISRC, [3]string{"TRC", "TSRC", "TSRC"}},
+	{FrameEncodingSettings, [3]string{"TSS", "TSSE", "TSSE"}},
+	{FrameUserText, [3]string{"TXX", "TXXX", "TXXX"}},
+	{FrameYear, [3]string{"TYE", "TYER", ""}},
+	{FrameUniqueFileID, [3]string{"UFI", "UFID", "UFID"}},
+	{FrameTermsOfUse, [3]string{"", "USER", "USER"}},
+	{FrameUnsynchronizedLyrics, [3]string{"ULT", "USLT", "USLT"}},
+	{FrameCommercialURL, [3]string{"WCM", "WCOM", "WCOM"}},
+	{FrameCopyrightURL, [3]string{"WCP", "WCOP", "WCOP"}},
+	{FrameAudioFileURL, [3]string{"WAF", "WOAF", "WOAF"}},
+	{FrameArtistURL, [3]string{"WAR", "WOAR", "WOAR"}},
+	{FrameAudioSourceURL, [3]string{"WAS", "WOAS", "WOAS"}},
+	{FrameRadioStationURL, [3]string{"", "WORS", "WORS"}},
+	{FramePaymentURL, [3]string{"", "WPAY", "WPAY"}},
+	{FramePublisherURL, [3]string{"WPB", "WPUB", "WPUB"}},
+	{FrameUserURL, [3]string{"WXX", "WXXX", "WXXX"}},
+}
+
+var (
+	kindsById = make(map[string]FrameKind)
+	idsByKind = make(map[FrameKind][3]string)
+)
+
+func init() {
+	for _, k := range frameKinds {
+		idsByKind[k.kind] = k.ids
+		for _, id := range k.ids {
+			if id != "" {
+				kindsById[id] = k.kind
+			}
+		}
+	}
+}
+
+// frameKindOf returns the kind of a frame ID from any version. IDs that are
+// not in the table are their own kind.
+func frameKindOf(id string) FrameKind {
+	if kind, ok := kindsById[id]; ok {
+		return kind
+	}
+	return FrameKind(id)
+}
+
+// ID returns the frame ID used for the kind in the given major version, or an
+// empty string if the version has no such frame. Kinds that are not in the
+// table are returned unchanged.
+func (kind FrameKind) ID(version uint8) string {
+	ids, ok := idsByKind[frameKindOf(string(kind))]
+	if !ok {
+		return string(kind)
+	}
+	if version < 2 || version > 4 {
+		return ""
+	}
+	return ids[version-2]
+}
@@ -0,0 +1,173 @@
+package id3
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const (
+	cdLeadOutTrack byte = 0xAA
+	// cdPregap is the two seconds before the first track that TOC addresses
+	// leave out but disc IDs count
+	cdPregap          uint32 = 150
+	cdFramesPerSecond uint32 = 75
+)
+
+// CDTOC is the table of contents of an audio CD. Offsets are logical block
+// addresses, counted in CD frames of 1/75 second from the start of the first
+// track.
+type CDTOC struct {
+	FirstTrack int
+	LastTrack  int
+	// Offsets holds the start of each track from FirstTrack to LastTrack
+	Offsets []uint32
+	LeadOut uint32
+}
+
+// ParseCDTOC reads a binary table of contents as returned by the READ TOC
+// command of CD drives, with LBA addresses: a four byte header followed by
+// eight bytes for each track and for the lead-out.
+func ParseCDTOC(data []byte) (*CDTOC, error) {
+	if len(data) < 4 {
+		return nil, ErrTooShort
+	}
+	length := int(binary.BigEndian.Uint16(data)) + 2
+	if length > len(data) {
+		return nil, ErrTooShort
+	}
+	toc := &CDTOC{FirstTrack: int(data[2]), LastTrack: int(data[3])}
+	if toc.FirstTrack < 1 || toc.LastTrack < toc.FirstTrack || toc.LastTrack > 99 {
+		return nil, errors.New(fmt.Sprintf("invalid CD tracks: %v-%v", toc.FirstTrack, toc.LastTrack))
+	}
+	hasLeadOut := false
+	for i := 4; i+8 <= length; i += 8 {
+		track := data[i+2]
+		address := binary.BigEndian.Uint32(data[i+4:])
+		switch {
+		case track == cdLeadOutTrack:
+			toc.LeadOut = address
+			hasLeadOut = true
+		case int(track) == toc.FirstTrack+len(toc.Offsets):
+			toc.Offsets = append(toc.Offsets, address)
+		default:
+			return nil, errors.New(fmt.Sprintf("unexpected CD track: %v", track))
+		}
+	}
+	if !hasLeadOut || len(toc.Offsets) != toc.LastTrack-toc.FirstTrack+1 {
+		return nil, errors.New("incomplete CD table of contents")
+	}
+	return toc, nil
+}
+
+// Bytes encodes the table of contents in the form ParseCDTOC reads.
+func (toc *CDTOC) Bytes() []byte {
+	length := 2 + 8*(len(toc.Offsets)+1)
+	b := make([]byte, 4, length+2)
+	binary.BigEndian.PutUint16(b, uint16(length))
+	b[2], b[3] = byte(toc.FirstTrack), byte(toc.LastTrack)
+	entry := func(track byte, address uint32) {
+		// Audio tracks with the current position in the Q sub-channel
+		b = append(b, 0, 0x10, track, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], address)
+	}
+	for i, offset := range toc.Offsets {
+		entry(byte(toc.FirstTrack+i), offset)
+	}
+	entry(cdLeadOutTrack, toc.LeadOut)
+	return b
+}
+
+// FreeDBID returns the freedb, or CDDB, disc ID as eight hexadecimal digits.
+func (toc *CDTOC) FreeDBID() string {
+	var n uint32
+	for _, offset := range toc.Offsets {
+		for s := (offset + cdPregap) / cdFramesPerSecond; s > 0; s /= 10 {
+			n += s % 10
+		}
+	}
+	var length uint32
+	if len(toc.Offsets) > 0 {
+		length = (toc.LeadOut+cdPregap)/cdFramesPerSecond - (toc.Offsets[0]+cdPregap)/cdFramesPerSecond
+	}
+	return fmt.Sprintf("%08x", (n%0xFF)<<24|length<<8|uint32(len(toc.Offsets)))
+}
+
+// MusicBrainzID returns the MusicBrainz disc ID.
+func (toc *CDTOC) MusicBrainzID() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%02X%02X%08X", toc.FirstTrack, toc.LastTrack, toc.LeadOut+cdPregap)
+	offsets := make([]uint32, 99)
+	for i, offset := range toc.Offsets {
+		if track := toc.FirstTrack + i; track <= len(offsets) {
+			offsets[track-1] = offset + cdPregap
+		}
+	}
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%08X", offset)
+	}
+	sum := sha1.Sum(b.Bytes())
+	// MusicBrainz uses its own URL safe alphabet
+	id := []byte(base64.StdEncoding.EncodeToString(sum[:]))
+	for i, c := range id {
+		switch c {
+		case '+':
+			id[i] = '.'
+		case '/':
+			id[i] = '_'
+		case '=':
+			id[i] = '-'
+		}
+	}
+	return string(id)
+}
+
+// MCDIFrame is an MCDI frame, or MCI in v2.2, holding the table of contents
+// of the CD the audio was taken from. The data is kept as read, since some
+// software stores it in other forms.
+type MCDIFrame struct {
+	frameBase
+
+	data []byte
+}
+
+func newMCDIFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	mf := &MCDIFrame{}
+	mf.header = header
+	mf.data = data
+	return mf, nil
+}
+
+func NewMCDIFrame(id string, toc *CDTOC) *MCDIFrame {
+	mf := &MCDIFrame{}
+	mf.data = toc.Bytes()
+	mf.header = newFrameHeader(id, 0, 0, uint32(len(mf.data)))
+	return mf
+}
+
+// TOC parses the table of contents held by the frame.
+func (mf *MCDIFrame) TOC() (*CDTOC, error) {
+	return ParseCDTOC(mf.data)
+}
+
+func (mf *MCDIFrame) String() string {
+	return hex.EncodeToString(mf.data)
+}
+
+func (mf *MCDIFrame) Bytes() []byte {
+	return mf.data
+}
+
+func (mf *MCDIFrame) encode(version uint8) ([]byte, error) {
+	return mf.data, nil
+}
+
+// MusicCDIdentifier returns the tag's MCDI frame, or nil.
+func (tag *Tag) MusicCDIdentifier() *MCDIFrame {
+	mf, _ := tag.Frame(FrameMusicCDIdentifier).(*MCDIFrame)
+	return mf
+}
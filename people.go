@@ -0,0 +1,98 @@
+package id3
+
+import (
+	"strings"
+)
+
+// InvolvedPerson is a credit from an involved people list: the role, such as
+// "producer" or an instrument, and the name of the person.
+type InvolvedPerson struct {
+	Role string
+	Name string
+}
+
+// InvolvedPeopleFrame is an IPLS frame, or one of the v2.4 TIPL and TMCL
+// frames that replace it, holding pairs of roles and names.
+type InvolvedPeopleFrame struct {
+	frameBase
+
+	people []InvolvedPerson
+}
+
+func newInvolvedPeopleFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	ipf := &InvolvedPeopleFrame{}
+	ipf.header = header
+	values, err := readStrings(data)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(values); i += 2 {
+		person := InvolvedPerson{Role: values[i]}
+		if i+1 < len(values) {
+			person.Name = values[i+1]
+		}
+		if person.Role == "" && person.Name == "" {
+			continue
+		}
+		ipf.people = append(ipf.people, person)
+	}
+	return ipf, nil
+}
+
+func NewInvolvedPeopleFrame(id string, people []InvolvedPerson) *InvolvedPeopleFrame {
+	ipf := &InvolvedPeopleFrame{}
+	ipf.header = newFrameHeader(id, 0, 0, 0)
+	ipf.people = people
+	return ipf
+}
+
+// People returns the credits in the order they are listed.
+func (ipf *InvolvedPeopleFrame) People() []InvolvedPerson {
+	people := make([]InvolvedPerson, len(ipf.people))
+	copy(people, ipf.people)
+	return people
+}
+
+func (ipf *InvolvedPeopleFrame) AddPerson(role string, name string) {
+	ipf.people = append(ipf.people, InvolvedPerson{role, name})
+}
+
+func (ipf *InvolvedPeopleFrame) String() string {
+	var credits []string
+	for _, person := range ipf.people {
+		credits = append(credits, person.Role+": "+person.Name)
+	}
+	return strings.Join(credits, ", ")
+}
+
+func (ipf *InvolvedPeopleFrame) Bytes() []byte {
+	return []byte(ipf.String())
+}
+
+func (ipf *InvolvedPeopleFrame) encode(version uint8) ([]byte, error) {
+	var values []string
+	for _, person := range ipf.people {
+		values = append(values, person.Role, person.Name)
+	}
+	return encodeStrings(version, values)
+}
+
+// Credits returns the involved people of the tag, from IPLS as well as TIPL
+// and TMCL frames, leaving out repeated credits.
+func (tag *Tag) Credits() []InvolvedPerson {
+	var credits []InvolvedPerson
+	seen := make(map[InvolvedPerson]bool)
+	for _, frame := range tag.frames {
+		ipf, ok := frame.(*InvolvedPeopleFrame)
+		if !ok {
+			continue
+		}
+		for _, person := range ipf.people {
+			if !seen[person] {
+				seen[person] = true
+				credits = append(credits, person)
+			}
+		}
+	}
+	return credits
+}
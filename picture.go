@@ -30,6 +30,28 @@ const (
 	PictureTypePublisherLogo
 )
 
+// PictureLinkMIME is the MIME type of pictures whose data is a URL linking to
+// the image rather than the image itself.
+const PictureLinkMIME = "-->"
+
+// pictureFormats maps the image formats of v2.2 PIC frames to MIME types.
+var pictureFormats = map[string]string{
+	"PNG": "image/png",
+	"JPG": "image/jpeg",
+	"GIF": "image/gif",
+	"BMP": "image/bmp",
+	"-->": PictureLinkMIME,
+}
+
+func pictureFormat(mime string) (string, bool) {
+	for imgFmt, m := range pictureFormats {
+		if m == mime {
+			return imgFmt, true
+		}
+	}
+	return "", false
+}
+
 type PictureFrame struct {
 	frameBase
 
@@ -69,19 +91,19 @@ func (pf *PictureFrame) read22(data []byte) error {
 		return err
 	}
 	imgFmt := string(data[1:4])
-	switch imgFmt {
-	case "PNG":
-		pf.mime = "image/png"
-	case "JPG":
-		pf.mime = "image/jpeg"
-	default:
+	mime, ok := pictureFormats[imgFmt]
+	if !ok {
 		return errors.New(fmt.Sprintf("Unknown picture format: %v", imgFmt))
 	}
+	pf.mime = mime
 	pf.pictureType = PictureType(data[4])
 	description, j, err := trimForEncoding(l-5, data[5:], textEncoding, false)
 	if err != nil {
 		return err
 	}
+	if 5+j > l {
+		return ErrTooShort
+	}
 	pf.description, err = decodeString(description, encoding)
 	pf.data = data[5+j:]
 	return nil
@@ -89,30 +111,66 @@ func (pf *PictureFrame) read22(data []byte) error {
 
 func (pf *PictureFrame) read23(data []byte) error {
 	l := len(data)
+	if l < 1 {
+		return ErrTooShort
+	}
 	textEncoding, encoding, err := extractEncoding(l, data)
 	if err != nil {
 		return err
 	}
 
 	mime, i, err := trimForEncoding(l, data, ISO88591, true)
-	i++
+	if err != nil {
+		return err
+	}
+	if i >= l {
+		return ErrTooShort
+	}
 
 	pf.mime, err = decodeString(mime, charmap.Windows1252)
 	if err != nil {
 		return err
 	}
 	pf.pictureType = PictureType(data[i])
+	i++
 
 	description, j, err := trimForEncoding(l-i, data[i:], textEncoding, false)
 	if err != nil {
 		return err
 	}
+	if i+j > l {
+		return ErrTooShort
+	}
 	pf.description, err = decodeString(description, encoding)
 
 	pf.data = data[i+j:]
 	return nil
 }
 
+func NewPictureFrame(id string, mime string, pictureType PictureType, description string, data []byte) *PictureFrame {
+	pf := &PictureFrame{}
+	pf.header = newFrameHeader(id, 0, 0, uint32(len(data)))
+	pf.mime = mime
+	pf.pictureType = pictureType
+	pf.description = description
+	pf.data = data
+	return pf
+}
+
+// MIMEType returns the type of the image, or PictureLinkMIME if the data is a
+// URL linking to the image instead.
+func (pf *PictureFrame) MIMEType() string {
+	return pf.mime
+}
+
+func (pf *PictureFrame) PictureType() PictureType {
+	return pf.pictureType
+}
+
+func (pf *PictureFrame) Description() string {
+	return pf.description
+}
+
 func (pf *PictureFrame) Bytes() []byte {
 	return pf.data
 }
@@ -120,3 +178,30 @@ func (pf *PictureFrame) Bytes() []byte {
 func (pf *PictureFrame) String() string {
 	return fmt.Sprintf("%v of type %v (%v bytes)", pf.description, pf.mime, len(pf.data))
 }
+
+func (pf *PictureFrame) encode(version uint8) ([]byte, error) {
+	textEncoding := encodingForVersion(version, pf.description)
+	b := []byte{byte(textEncoding)}
+	var err error
+	switch version {
+	case 2:
+		imgFmt, ok := pictureFormat(pf.mime)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("Unknown picture format: %v", pf.mime))
+		}
+		b = append(b, imgFmt...)
+	case 3, 4:
+		b, err = appendString(b, pf.mime, ISO88591, true)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown picture frame revision: %v", version))
+	}
+	b = append(b, byte(pf.pictureType))
+	b, err = appendString(b, pf.description, textEncoding, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, pf.data...), nil
+}
@@ -0,0 +1,65 @@
+package id3
+
+import (
+	"math/big"
+)
+
+// PlayCounterFrame is a PCNT frame counting how many times the file has been
+// played. The counter is stored big-endian and grows beyond 32 bits as needed.
+type PlayCounterFrame struct {
+	frameBase
+
+	count *big.Int
+}
+
+func newPlayCounterFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	pcf := &PlayCounterFrame{}
+	pcf.header = header
+	pcf.count = new(big.Int).SetBytes(data)
+	return pcf, nil
+}
+
+func NewPlayCounterFrame(id string, count uint64) *PlayCounterFrame {
+	pcf := &PlayCounterFrame{}
+	pcf.header = newFrameHeader(id, 0, 0, 0)
+	pcf.count = new(big.Int).SetUint64(count)
+	return pcf
+}
+
+// Count returns the play count, or math.MaxUint64 if it does not fit in a
+// uint64; BigCount returns such counts in full.
+func (pcf *PlayCounterFrame) Count() uint64 {
+	return counterUint64(pcf.count)
+}
+
+func (pcf *PlayCounterFrame) BigCount() *big.Int {
+	return new(big.Int).Set(pcf.count)
+}
+
+func (pcf *PlayCounterFrame) SetCount(count uint64) {
+	pcf.count.SetUint64(count)
+}
+
+func (pcf *PlayCounterFrame) SetBigCount(count *big.Int) {
+	pcf.count.Set(count)
+}
+
+// Increment adds one play to the counter.
+func (pcf *PlayCounterFrame) Increment() {
+	pcf.count.Add(pcf.count, big.NewInt(1))
+}
+
+func (pcf *PlayCounterFrame) String() string {
+	return pcf.count.String()
+}
+
+func (pcf *PlayCounterFrame) Bytes() []byte {
+	return encodeCounter(pcf.count)
+}
+
+func (pcf *PlayCounterFrame) encode(version uint8) ([]byte, error) {
+	if pcf.count.Sign() < 0 {
+		return nil, ErrNegativeCounter
+	}
+	return pcf.Bytes(), nil
+}
@@ -0,0 +1,150 @@
+package id3
+
+import (
+	"fmt"
+	"math/big"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// WindowsMediaPlayerEmail is the email Windows Media Player stores its
+// ratings under.
+const WindowsMediaPlayerEmail = "Windows Media Player 9 Series"
+
+// PopularimeterFrame is a POPM frame: a rating from 1 (worst) to 255 (best),
+// or 0 for unrated, along with a play counter, both kept per user email.
+type PopularimeterFrame struct {
+	frameBase
+
+	email   string
+	rating  byte
+	counter *big.Int
+}
+
+func newPopularimeterFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	pf := &PopularimeterFrame{}
+	pf.header = header
+
+	email, i, err := trimForEncoding(len(data), data, ISO88591, false)
+	if err != nil {
+		return nil, err
+	}
+	if i >= len(data) {
+		return nil, ErrTooShort
+	}
+	pf.email, err = decodeString(email, charmap.Windows1252)
+	if err != nil {
+		return nil, err
+	}
+	pf.rating = data[i]
+	// The counter is optional and may be any length
+	pf.counter = new(big.Int).SetBytes(data[i+1:])
+	return pf, nil
+}
+
+func NewPopularimeterFrame(id string, email string, rating byte, counter uint64) *PopularimeterFrame {
+	pf := &PopularimeterFrame{}
+	pf.header = newFrameHeader(id, 0, 0, 0)
+	pf.email = email
+	pf.rating = rating
+	pf.counter = new(big.Int).SetUint64(counter)
+	return pf
+}
+
+func (pf *PopularimeterFrame) Email() string {
+	return pf.email
+}
+
+func (pf *PopularimeterFrame) Rating() byte {
+	return pf.rating
+}
+
+func (pf *PopularimeterFrame) SetRating(rating byte) {
+	pf.rating = rating
+}
+
+// Counter returns the play counter, or math.MaxUint64 if it does not fit in a
+// uint64; BigCounter returns such counters in full.
+func (pf *PopularimeterFrame) Counter() uint64 {
+	return counterUint64(pf.counter)
+}
+
+func (pf *PopularimeterFrame) BigCounter() *big.Int {
+	return new(big.Int).Set(pf.counter)
+}
+
+func (pf *PopularimeterFrame) SetCounter(counter uint64) {
+	pf.counter.SetUint64(counter)
+}
+
+func (pf *PopularimeterFrame) SetBigCounter(counter *big.Int) {
+	pf.counter.Set(counter)
+}
+
+// Stars returns the rating on the 0 to 5 star scale used by Windows Media
+// Player, foobar2000 and MediaMonkey, where 0 means unrated.
+func (pf *PopularimeterFrame) Stars() int {
+	return RatingToStars(pf.rating)
+}
+
+// SetStars sets the rating from a 0 to 5 star value.
+func (pf *PopularimeterFrame) SetStars(stars int) {
+	pf.rating = StarsToRating(stars)
+}
+
+// RatingToStars maps a popularimeter rating to 0 to 5 stars. The ranges
+// around the values StarsToRating writes are shared by most players.
+func RatingToStars(rating byte) int {
+	switch {
+	case rating == 0:
+		return 0
+	case rating < 32:
+		return 1
+	case rating < 96:
+		return 2
+	case rating < 160:
+		return 3
+	case rating < 224:
+		return 4
+	}
+	return 5
+}
+
+// StarsToRating maps 0 to 5 stars to the rating Windows Media Player writes
+// for them. Values outside the range are clamped.
+func StarsToRating(stars int) byte {
+	switch {
+	case stars <= 0:
+		return 0
+	case stars == 1:
+		return 1
+	case stars == 2:
+		return 64
+	case stars == 3:
+		return 128
+	case stars == 4:
+		return 196
+	}
+	return 255
+}
+
+func (pf *PopularimeterFrame) String() string {
+	return fmt.Sprintf("%v (%v, played %v)", pf.rating, pf.email, pf.counter)
+}
+
+func (pf *PopularimeterFrame) Bytes() []byte {
+	b, _ := pf.encode(0)
+	return b
+}
+
+func (pf *PopularimeterFrame) encode(version uint8) ([]byte, error) {
+	b, err := appendString(nil, pf.email, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	if pf.counter.Sign() < 0 {
+		return nil, ErrNegativeCounter
+	}
+	b = append(b, pf.rating)
+	return append(b, encodeCounter(pf.counter)...), nil
+}
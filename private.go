@@ -0,0 +1,138 @@
+package id3
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Owners of the PRIV frames Windows Media Player writes.
+const (
+	OwnerMediaClassPrimaryID   = "WM/MediaClassPrimaryID"
+	OwnerMediaClassSecondaryID = "WM/MediaClassSecondaryID"
+	OwnerWMContentID           = "WM/WMContentID"
+	OwnerWMCollectionID        = "WM/WMCollectionID"
+	OwnerWMCollectionGroupID   = "WM/WMCollectionGroupID"
+	OwnerProvider              = "WM/Provider"
+	OwnerUniqueFileIdentifier  = "WM/UniqueFileIdentifier"
+	OwnerAverageLevel          = "AverageLevel"
+	OwnerPeakValue             = "PeakValue"
+)
+
+// PrivateFrame is a PRIV frame, holding data in a format known only to its
+// owner, which is usually an email address or URL.
+type PrivateFrame struct {
+	frameBase
+
+	owner string
+	data  []byte
+}
+
+func newPrivateFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	pf := &PrivateFrame{}
+	pf.header = header
+
+	l := len(data)
+	owner, i, err := trimForEncoding(l, data, ISO88591, false)
+	if err != nil {
+		return nil, err
+	}
+	if i > l {
+		return nil, ErrTooShort
+	}
+	pf.owner, err = decodeString(owner, charmap.Windows1252)
+	if err != nil {
+		return nil, err
+	}
+	pf.data = data[i:]
+	return pf, nil
+}
+
+func NewPrivateFrame(id string, owner string, data []byte) *PrivateFrame {
+	pf := &PrivateFrame{}
+	pf.header = newFrameHeader(id, 0, 0, uint32(len(data)))
+	pf.owner = owner
+	pf.data = data
+	return pf
+}
+
+func (pf *PrivateFrame) Owner() string {
+	return pf.owner
+}
+
+func (pf *PrivateFrame) Data() []byte {
+	return pf.data
+}
+
+// GUID decodes the data as a Windows GUID, as stored by the WM/ owners that
+// identify media classes and collections.
+func (pf *PrivateFrame) GUID() (string, error) {
+	d := pf.data
+	if len(d) != 16 {
+		return "", errors.New(fmt.Sprintf("invalid GUID length: %v", len(d)))
+	}
+	// The first three groups are little-endian
+	return fmt.Sprintf("{%08X-%04X-%04X-%X-%X}",
+		binary.LittleEndian.Uint32(d[0:4]),
+		binary.LittleEndian.Uint16(d[4:6]),
+		binary.LittleEndian.Uint16(d[6:8]),
+		d[8:10], d[10:16]), nil
+}
+
+// Uint32 decodes the data as the little-endian integer stored by the
+// AverageLevel and PeakValue owners.
+func (pf *PrivateFrame) Uint32() (uint32, error) {
+	if len(pf.data) != 4 {
+		return 0, errors.New(fmt.Sprintf("invalid integer length: %v", len(pf.data)))
+	}
+	return binary.LittleEndian.Uint32(pf.data), nil
+}
+
+// UTF16String decodes the data as the null terminated UTF-16LE string stored
+// by owners such as WM/Provider.
+func (pf *PrivateFrame) UTF16String() (string, error) {
+	data := pf.data[:len(pf.data)&^1]
+	data, _, err := trimForEncoding(len(data), data, UTF16, false)
+	if err != nil {
+		return "", err
+	}
+	return decodeString(data, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM))
+}
+
+// Value decodes the data of the well known owners: a GUID string, a uint32 or
+// a string. The data of other owners is returned as is.
+func (pf *PrivateFrame) Value() (interface{}, error) {
+	switch pf.owner {
+	case OwnerMediaClassPrimaryID, OwnerMediaClassSecondaryID, OwnerWMContentID, OwnerWMCollectionID, OwnerWMCollectionGroupID:
+		return pf.GUID()
+	case OwnerAverageLevel, OwnerPeakValue:
+		return pf.Uint32()
+	case OwnerProvider, OwnerUniqueFileIdentifier:
+		return pf.UTF16String()
+	}
+	return pf.data, nil
+}
+
+func (pf *PrivateFrame) String() string {
+	if v, err := pf.Value(); err == nil {
+		if _, raw := v.([]byte); !raw {
+			return fmt.Sprintf("%v: %v", pf.owner, v)
+		}
+	}
+	return fmt.Sprintf("%v (%v bytes)", pf.owner, len(pf.data))
+}
+
+func (pf *PrivateFrame) Bytes() []byte {
+	return pf.data
+}
+
+func (pf *PrivateFrame) encode(version uint8) ([]byte, error) {
+	b, err := appendString(nil, pf.owner, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, pf.data...), nil
+}
@@ -0,0 +1,50 @@
+package id3
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// SeekFrame is the v2.4 SEEK frame, which points to a further tag later in
+// the stream.
+type SeekFrame struct {
+	frameBase
+
+	offset uint32
+}
+
+func newSeekFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	if len(data) < 4 {
+		return nil, ErrTooShort
+	}
+	sf := &SeekFrame{}
+	sf.header = header
+	sf.offset = binary.BigEndian.Uint32(data)
+	return sf, nil
+}
+
+func NewSeekFrame(offset uint32) *SeekFrame {
+	sf := &SeekFrame{}
+	sf.header = newFrameHeader(FrameSeek.ID(4), 0, 0, 4)
+	sf.offset = offset
+	return sf
+}
+
+// Offset returns the minimum offset from the end of this tag to the next one.
+func (sf *SeekFrame) Offset() uint32 {
+	return sf.offset
+}
+
+func (sf *SeekFrame) String() string {
+	return fmt.Sprintf("%v", sf.offset)
+}
+
+func (sf *SeekFrame) Bytes() []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, sf.offset)
+	return b
+}
+
+func (sf *SeekFrame) encode(version uint8) ([]byte, error) {
+	return sf.Bytes(), nil
+}
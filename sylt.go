@@ -0,0 +1,289 @@
+package id3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// TimestampFormat is the unit of the timestamps in synchronised frames.
+type TimestampFormat byte
+
+const (
+	TimestampMPEGFrames   TimestampFormat = 1
+	TimestampMilliseconds TimestampFormat = 2
+)
+
+// LyricsContentType describes what the text of a SYLT frame holds.
+type LyricsContentType byte
+
+const (
+	LyricsContentOther LyricsContentType = iota
+	LyricsContentLyrics
+	LyricsContentTranscription
+	LyricsContentMovement
+	LyricsContentEvents
+	LyricsContentChord
+	LyricsContentTrivia
+	LyricsContentWebpageURLs
+	LyricsContentImageURLs
+)
+
+// SyncedText is a piece of text and the time it starts, in the frame's
+// timestamp format.
+type SyncedText struct {
+	Text      string
+	Timestamp uint32
+}
+
+// SynchronizedLyricsFrame is a SYLT frame, holding lyrics or other text
+// synchronised with the audio.
+type SynchronizedLyricsFrame struct {
+	frameBase
+
+	language        language.Base
+	timestampFormat TimestampFormat
+	contentType     LyricsContentType
+	description     string
+	entries         []SyncedText
+}
+
+func newSynchronizedLyricsFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	slf := &SynchronizedLyricsFrame{}
+	slf.header = header
+
+	l := len(data)
+	if l < 6 {
+		return nil, ErrTooShort
+	}
+	textEncoding, encoding, err := extractEncoding(l, data)
+	if err != nil {
+		return nil, err
+	}
+	slf.language, err = readLanguage(data[1:4])
+	if err != nil {
+		return nil, err
+	}
+	slf.timestampFormat = TimestampFormat(data[4])
+	slf.contentType = LyricsContentType(data[5])
+
+	data = data[6:]
+	description, i, err := trimForEncoding(len(data), data, textEncoding, false)
+	if err != nil {
+		return nil, err
+	}
+	if i > len(data) {
+		return nil, ErrTooShort
+	}
+	slf.description, err = decodeString(description, encoding)
+	if err != nil {
+		return nil, err
+	}
+	for i+4 < len(data) {
+		data = data[i:]
+		var text []byte
+		text, i, err = trimForEncoding(len(data), data, textEncoding, false)
+		if err != nil {
+			return nil, err
+		}
+		if i+4 > len(data) {
+			return nil, ErrTooShort
+		}
+		entry := SyncedText{Timestamp: binary.BigEndian.Uint32(data[i:])}
+		entry.Text, err = decodeString(text, encoding)
+		if err != nil {
+			return nil, err
+		}
+		slf.entries = append(slf.entries, entry)
+		i += 4
+	}
+	return slf, nil
+}
+
+// NewSynchronizedLyricsFrame creates a frame of lyrics with millisecond
+// timestamps.
+func NewSynchronizedLyricsFrame(id string, lang language.Base, description string, entries []SyncedText) *SynchronizedLyricsFrame {
+	slf := &SynchronizedLyricsFrame{}
+	slf.header = newFrameHeader(id, 0, 0, 0)
+	slf.language = lang
+	slf.timestampFormat = TimestampMilliseconds
+	slf.contentType = LyricsContentLyrics
+	slf.description = description
+	slf.entries = entries
+	return slf
+}
+
+func (slf *SynchronizedLyricsFrame) Language() language.Base {
+	return slf.language
+}
+
+func (slf *SynchronizedLyricsFrame) TimestampFormat() TimestampFormat {
+	return slf.timestampFormat
+}
+
+func (slf *SynchronizedLyricsFrame) ContentType() LyricsContentType {
+	return slf.contentType
+}
+
+func (slf *SynchronizedLyricsFrame) SetContentType(contentType LyricsContentType) {
+	slf.contentType = contentType
+}
+
+func (slf *SynchronizedLyricsFrame) Description() string {
+	return slf.description
+}
+
+func (slf *SynchronizedLyricsFrame) Entries() []SyncedText {
+	entries := make([]SyncedText, len(slf.entries))
+	copy(entries, slf.entries)
+	return entries
+}
+
+// LRC formats the entries as LRC lyrics. Only frames with millisecond
+// timestamps can be converted.
+func (slf *SynchronizedLyricsFrame) LRC() (string, error) {
+	if slf.timestampFormat != TimestampMilliseconds {
+		return "", ErrTimestampFormat
+	}
+	return FormatLRC(slf.entries), nil
+}
+
+func (slf *SynchronizedLyricsFrame) String() string {
+	var texts []string
+	for _, entry := range slf.entries {
+		texts = append(texts, strings.TrimLeft(entry.Text, "\r\n"))
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (slf *SynchronizedLyricsFrame) Bytes() []byte {
+	return []byte(slf.String())
+}
+
+func (slf *SynchronizedLyricsFrame) encode(version uint8) ([]byte, error) {
+	values := []string{slf.description}
+	for _, entry := range slf.entries {
+		values = append(values, entry.Text)
+	}
+	textEncoding := encodingForVersion(version, values...)
+	b := []byte{byte(textEncoding)}
+	b = append(b, languageCode(slf.language)...)
+	b = append(b, byte(slf.timestampFormat), byte(slf.contentType))
+	b, err := appendString(b, slf.description, textEncoding, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range slf.entries {
+		b, err = appendString(b, entry.Text, textEncoding, true)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], entry.Timestamp)
+	}
+	return b, nil
+}
+
+// ParseLRC reads LRC lyrics into entries with millisecond timestamps, sorted
+// by time. Lines with several timestamps produce an entry for each, and the
+// offset tag is applied; other ID tags are ignored.
+func ParseLRC(r io.Reader) ([]SyncedText, error) {
+	var entries []SyncedText
+	var offset int64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var times []int64
+		for strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				break
+			}
+			field := line[1:end]
+			line = line[end+1:]
+			if strings.HasPrefix(field, "offset:") {
+				o, err := strconv.ParseInt(strings.TrimSpace(field[len("offset:"):]), 10, 64)
+				if err != nil {
+					return nil, errors.New(fmt.Sprintf("invalid LRC offset: %v", field))
+				}
+				offset = o
+				continue
+			}
+			if t, ok := parseLRCTime(field); ok {
+				times = append(times, t)
+			}
+		}
+		for _, t := range times {
+			entries = append(entries, SyncedText{Text: line, Timestamp: uint32(t)})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// A positive offset makes the lyrics appear sooner
+	for i := range entries {
+		t := int64(entries[i].Timestamp) - offset
+		if t < 0 {
+			t = 0
+		}
+		entries[i].Timestamp = uint32(t)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+	return entries, nil
+}
+
+// parseLRCTime parses an mm:ss, mm:ss.xx or mm:ss.xxx time in milliseconds.
+func parseLRCTime(field string) (int64, bool) {
+	colon := strings.Index(field, ":")
+	if colon < 1 {
+		return 0, false
+	}
+	minutes, err := strconv.ParseInt(field[:colon], 10, 64)
+	if err != nil || minutes < 0 {
+		return 0, false
+	}
+	seconds := field[colon+1:]
+	var fraction string
+	if dot := strings.IndexAny(seconds, ".:"); dot >= 0 {
+		seconds, fraction = seconds[:dot], seconds[dot+1:]
+	}
+	s, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil || s < 0 || s >= 60 {
+		return 0, false
+	}
+	var ms int64
+	if fraction != "" {
+		if len(fraction) > 3 {
+			fraction = fraction[:3]
+		}
+		ms, err = strconv.ParseInt(fraction, 10, 64)
+		if err != nil || ms < 0 {
+			return 0, false
+		}
+		for i := len(fraction); i < 3; i++ {
+			ms *= 10
+		}
+	}
+	return (minutes*60+s)*1000 + ms, true
+}
+
+// FormatLRC writes entries with millisecond timestamps as LRC lyrics, one
+// line per entry.
+func FormatLRC(entries []SyncedText) string {
+	var b bytes.Buffer
+	for _, entry := range entries {
+		t := entry.Timestamp / 10
+		fmt.Fprintf(&b, "[%02d:%02d.%02d]%v\n", t/6000, t/100%60, t%100, strings.Trim(entry.Text, "\r\n"))
+	}
+	return b.String()
+}
@@ -1,9 +1,18 @@
 package id3
 
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
 type Tag struct {
 	Header         *Header
 	ExtendedHeader *ExtendedHeader
 
+	offset        int64
+	frames        []Frame
 	frameMap      map[string][]Frame
 	titleFrame    Frame
 	artistFrame   Frame
@@ -28,28 +37,339 @@ func emptyTag() *Tag {
 }
 
 func (tag *Tag) addFrame(frame Frame) {
+	tag.frames = append(tag.frames, frame)
 	id := frame.Id()
-	frames, ok := tag.frameMap[id]
-	frames = append(frames, frame)
-	if !ok {
-		tag.frameMap[id] = frames
-	}
-	switch id {
-	case "TT2", "TIT2":
-		tag.titleFrame = frame
-	case "TP1", "TPE1":
-		tag.artistFrame = frame
-	case "TAL", "TALB":
-		tag.albumFrame = frame
-	case "TYE", "TYER":
-		tag.yearFrame = frame
-	case "TCO", "TCON":
-		tag.genreFrame = frame
-	case "COM", "COMM":
+	tag.frameMap[id] = append(tag.frameMap[id], frame)
+	if core := tag.coreFrame(id); core != nil {
+		*core = frame
+	}
+	if frameKindOf(id) == FrameComments {
 		tag.commentFrames = append(tag.commentFrames, frame)
 	}
 }
 
+func (tag *Tag) coreFrame(id string) *Frame {
+	switch frameKindOf(id) {
+	case FrameTitle:
+		return &tag.titleFrame
+	case FrameArtist:
+		return &tag.artistFrame
+	case FrameAlbum:
+		return &tag.albumFrame
+	case FrameYear, FrameRecordingTime:
+		return &tag.yearFrame
+	case FrameGenre:
+		return &tag.genreFrame
+	}
+	return nil
+}
+
+// version returns the major version the tag's frame IDs belong to. Tags read
+// from ID3v1 have no header and are built from v2.2 frames.
+func (tag *Tag) version() uint8 {
+	if tag.Header == nil {
+		return 2
+	}
+	return tag.Header.version
+}
+
+// frameId returns the ID used for kind in this tag's version, falling back to
+// the kind itself for frames the version does not define.
+func (tag *Tag) frameId(kind FrameKind) string {
+	if id := kind.ID(tag.version()); id != "" {
+		return id
+	}
+	return string(kind)
+}
+
+// framesOf returns the frames of the given kind, whichever version's ID they
+// were stored under.
+func (tag *Tag) framesOf(kind FrameKind) []Frame {
+	kind = frameKindOf(string(kind))
+	ids, ok := idsByKind[kind]
+	if !ok {
+		return tag.frameMap[string(kind)]
+	}
+	var found []string
+	for _, id := range ids {
+		if _, ok := tag.frameMap[id]; ok && id != "" {
+			found = append(found, id)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil
+	case 1:
+		return tag.frameMap[found[0]]
+	}
+	var frames []Frame
+	for _, frame := range tag.frames {
+		if frameKindOf(frame.Id()) == kind {
+			frames = append(frames, frame)
+		}
+	}
+	return frames
+}
+
+// Frames returns every frame in the tag, in the order they appear in the file.
+func (tag *Tag) Frames() []Frame {
+	frames := make([]Frame, len(tag.frames))
+	copy(frames, tag.frames)
+	return frames
+}
+
+// Frame returns the first frame of the given kind, or nil if there is none.
+// Frame IDs from any version may be used, so Frame("TT2") and
+// Frame(FrameTitle) both find the title of a v2.3 tag.
+func (tag *Tag) Frame(kind FrameKind) Frame {
+	frames := tag.framesOf(kind)
+	if len(frames) == 0 {
+		return nil
+	}
+	return frames[0]
+}
+
+// FramesByID returns every frame of the given kind, in file order.
+func (tag *Tag) FramesByID(kind FrameKind) []Frame {
+	found := tag.framesOf(kind)
+	frames := make([]Frame, len(found))
+	copy(frames, found)
+	return frames
+}
+
+// FrameIDs returns the distinct frame IDs in the tag, in order of first appearance.
+func (tag *Tag) FrameIDs() []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, frame := range tag.frames {
+		id := frame.Id()
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// AddFrame appends a frame to the end of the tag's frames.
+func (tag *Tag) AddFrame(frame Frame) {
+	tag.addFrame(frame)
+}
+
+// RemoveFrames removes every frame of the given kind from the tag.
+func (tag *Tag) RemoveFrames(kind FrameKind) {
+	kind = frameKindOf(string(kind))
+	frames := tag.frames[:0]
+	for _, frame := range tag.frames {
+		if frameKindOf(frame.Id()) == kind {
+			delete(tag.frameMap, frame.Id())
+			if core := tag.coreFrame(frame.Id()); core != nil && *core == frame {
+				*core = nil
+			}
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	for i := len(frames); i < len(tag.frames); i++ {
+		tag.frames[i] = nil
+	}
+	tag.frames = frames
+
+	if kind == FrameComments {
+		tag.commentFrames = nil
+	}
+}
+
+// ReplaceFrame replaces every frame of the same kind as frame with frame itself.
+func (tag *Tag) ReplaceFrame(frame Frame) {
+	tag.RemoveFrames(FrameKind(frame.Id()))
+	tag.addFrame(frame)
+}
+
+func (tag *Tag) setText(kind FrameKind, values ...string) {
+	if len(values) == 0 || len(values) == 1 && values[0] == "" {
+		tag.RemoveFrames(kind)
+		return
+	}
+	tag.ReplaceFrame(NewTextFrame(tag.frameId(kind), values...))
+}
+
+func (tag *Tag) SetTitle(title string) {
+	tag.setText(FrameTitle, title)
+}
+
+func (tag *Tag) SetArtist(artist string) {
+	tag.setText(FrameArtist, artist)
+}
+
+func (tag *Tag) SetAlbum(album string) {
+	tag.setText(FrameAlbum, album)
+}
+
+func (tag *Tag) SetYear(year string) {
+	if tag.version() >= 4 {
+		tag.setText(FrameRecordingTime, year)
+		return
+	}
+	tag.setText(FrameYear, year)
+}
+
+func (tag *Tag) SetGenre(genre string) {
+	tag.setText(FrameGenre, genre)
+}
+
+// SetArtists sets the lead artists. v2.4 tags store each as a separate value,
+// earlier versions separate them with "/".
+func (tag *Tag) SetArtists(artists ...string) {
+	tag.setText(FrameArtist, artists...)
+}
+
+func (tag *Tag) SetGenres(genres ...string) {
+	tag.setText(FrameGenre, genres...)
+}
+
+func (tag *Tag) AddComment(lang language.Base, description string, text string) {
+	tag.addFrame(NewFullTextFrame(tag.frameId(FrameComments), lang, description, text))
+}
+
+// Popularimeter returns the popularimeter kept for the given email, or nil.
+func (tag *Tag) Popularimeter(email string) *PopularimeterFrame {
+	for _, frame := range tag.framesOf(FramePopularimeter) {
+		if pf, ok := frame.(*PopularimeterFrame); ok && pf.Email() == email {
+			return pf
+		}
+	}
+	return nil
+}
+
+// SetRating sets the rating kept for the given email, adding a popularimeter
+// if there is none yet.
+func (tag *Tag) SetRating(email string, rating byte) {
+	if pf := tag.Popularimeter(email); pf != nil {
+		pf.SetRating(rating)
+		return
+	}
+	tag.addFrame(NewPopularimeterFrame(tag.frameId(FramePopularimeter), email, rating, 0))
+}
+
+// PlayCount returns the count from the tag's play counter, or 0 if it has none.
+func (tag *Tag) PlayCount() uint64 {
+	if pcf, ok := tag.Frame(FramePlayCounter).(*PlayCounterFrame); ok {
+		return pcf.Count()
+	}
+	return 0
+}
+
+// IncrementPlayCount adds one play to the tag's play counter, adding the
+// counter if there is none yet.
+func (tag *Tag) IncrementPlayCount() {
+	if pcf, ok := tag.Frame(FramePlayCounter).(*PlayCounterFrame); ok {
+		pcf.Increment()
+		return
+	}
+	tag.addFrame(NewPlayCounterFrame(tag.frameId(FramePlayCounter), 1))
+}
+
+// URLs returns the URLs held by the link frames of the given kind.
+func (tag *Tag) URLs(kind FrameKind) []string {
+	var urls []string
+	for _, frame := range tag.framesOf(kind) {
+		switch f := frame.(type) {
+		case *URLFrame:
+			urls = append(urls, f.URL())
+		case *UserURLFrame:
+			urls = append(urls, f.URL())
+		}
+	}
+	return urls
+}
+
+// AddURL adds a link frame of the given kind, such as FrameArtistURL.
+func (tag *Tag) AddURL(kind FrameKind, url string) {
+	tag.addFrame(NewURLFrame(tag.frameId(kind), url))
+}
+
+func (tag *Tag) ArtistURLs() []string {
+	return tag.URLs(FrameArtistURL)
+}
+
+func (tag *Tag) CommercialURLs() []string {
+	return tag.URLs(FrameCommercialURL)
+}
+
+// UserURL returns the URL of the WXXX frame with the given description.
+func (tag *Tag) UserURL(description string) string {
+	for _, frame := range tag.framesOf(FrameUserURL) {
+		if uuf, ok := frame.(*UserURLFrame); ok && uuf.Description() == description {
+			return uuf.URL()
+		}
+	}
+	return ""
+}
+
+// SetUserURL replaces the WXXX frame with the given description, or removes
+// it if url is empty.
+func (tag *Tag) SetUserURL(description string, url string) {
+	var frames []Frame
+	for _, frame := range tag.frames {
+		if uuf, ok := frame.(*UserURLFrame); ok && uuf.Description() == description {
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	if len(frames) != len(tag.frames) {
+		tag.setFrames(frames)
+	}
+	if url != "" {
+		tag.addFrame(NewUserURLFrame(tag.frameId(FrameUserURL), description, url))
+	}
+}
+
+// SynchronizedLyrics returns the tag's SYLT frames.
+func (tag *Tag) SynchronizedLyrics() []*SynchronizedLyricsFrame {
+	var lyrics []*SynchronizedLyricsFrame
+	for _, frame := range tag.framesOf(FrameSynchronizedLyrics) {
+		if slf, ok := frame.(*SynchronizedLyricsFrame); ok {
+			lyrics = append(lyrics, slf)
+		}
+	}
+	return lyrics
+}
+
+// Objects returns the tag's GEOB frames.
+func (tag *Tag) Objects() []*GeneralObjectFrame {
+	var objects []*GeneralObjectFrame
+	for _, frame := range tag.framesOf(FrameGeneralObject) {
+		if gof, ok := frame.(*GeneralObjectFrame); ok {
+			objects = append(objects, gof)
+		}
+	}
+	return objects
+}
+
+// Object returns the GEOB frame with the given description, or nil. Content
+// descriptions are unique within a tag.
+func (tag *Tag) Object(description string) *GeneralObjectFrame {
+	for _, gof := range tag.Objects() {
+		if gof.Description() == description {
+			return gof
+		}
+	}
+	return nil
+}
+
+// PrivateFrames returns the tag's PRIV frames with the given owner, or every
+// PRIV frame if owner is empty.
+func (tag *Tag) PrivateFrames(owner string) []*PrivateFrame {
+	var frames []*PrivateFrame
+	for _, frame := range tag.framesOf(FramePrivate) {
+		if pf, ok := frame.(*PrivateFrame); ok && (owner == "" || pf.Owner() == owner) {
+			frames = append(frames, pf)
+		}
+	}
+	return frames
+}
+
 func (tag *Tag) Title() string {
 	if tag.titleFrame != nil {
 		return tag.titleFrame.String()
@@ -89,6 +409,37 @@ func (tag *Tag) Genre() string {
 
 }
 
+// Artists returns each of the lead artists. Before v2.4 artists are
+// separated by "/", so names containing one are split too.
+func (tag *Tag) Artists() []string {
+	tf, ok := tag.artistFrame.(*TextFrame)
+	if !ok {
+		return nil
+	}
+	if tag.version() >= 4 {
+		return tf.Values()
+	}
+	var artists []string
+	for _, value := range tf.values {
+		artists = append(artists, strings.Split(value, "/")...)
+	}
+	return artists
+}
+
+// Genres returns each of the genres, with references to ID3v1 genres such as
+// "(17)" or "17" replaced by their names.
+func (tag *Tag) Genres() []string {
+	tf, ok := tag.genreFrame.(*TextFrame)
+	if !ok {
+		return nil
+	}
+	var genres []string
+	for _, value := range tf.values {
+		genres = append(genres, parseGenres(value)...)
+	}
+	return genres
+}
+
 func (tag *Tag) Comments() []string {
 	if len(tag.commentFrames) > 0 {
 		var comments []string
@@ -104,20 +455,61 @@ func (tag *Tag) missingCoreInfo() bool {
 	return tag.albumFrame == nil || tag.artistFrame == nil || tag.genreFrame == nil || tag.titleFrame == nil || tag.yearFrame == nil
 }
 
+// mergeTag fills in the core frames the tag is missing from tag2, usually an
+// ID3v1 tag. The frames are converted to the tag's version and added to its
+// frames, so that they are written and removed along with the others.
 func (tag *Tag) mergeTag(tag2 *Tag) {
-	if tag.albumFrame == nil && tag2.albumFrame != nil {
-		tag.albumFrame = tag2.albumFrame
+	merged := tag2.copy()
+	if _, err := merged.ConvertTo(tag.version()); err != nil {
+		return
+	}
+	for _, core := range []struct {
+		frame  Frame
+		merged Frame
+	}{
+		{tag.albumFrame, merged.albumFrame},
+		{tag.artistFrame, merged.artistFrame},
+		{tag.genreFrame, merged.genreFrame},
+		{tag.titleFrame, merged.titleFrame},
+		{tag.yearFrame, merged.yearFrame},
+	} {
+		if core.frame == nil && core.merged != nil && core.merged.String() != "" {
+			tag.addFrame(core.merged)
+		}
 	}
-	if tag.artistFrame == nil && tag2.artistFrame != nil {
-		tag.artistFrame = tag2.artistFrame
+}
+
+// Marshal returns the tag encoded as an ID3v2 tag of the given major version,
+// including header and padding. The frame IDs must already belong to that
+// version; use ConvertTo to change the version of a tag.
+func (tag *Tag) Marshal(version uint8) ([]byte, error) {
+	params, err := paramsForVersion(version)
+	if err != nil {
+		return nil, err
 	}
-	if tag.genreFrame == nil && tag2.genreFrame != nil {
-		tag.genreFrame = tag2.genreFrame
+	padding := defaultPaddingSize
+	if tag.Header != nil && tag.Header.paddingSize > 0 {
+		padding = tag.Header.paddingSize
 	}
-	if tag.titleFrame == nil && tag2.titleFrame != nil {
-		tag.titleFrame = tag2.titleFrame
+	return tag.writeV2(version, padding, params)
+}
+
+// WriteTo writes the tag using the version from its header. A tag without a
+// header, such as one read from an ID3v1 tag, is written as a copy converted
+// to ID3v2.4, leaving the tag itself unchanged.
+func (tag *Tag) WriteTo(w io.Writer) (int64, error) {
+	if tag.Header == nil {
+		converted := tag.copy()
+		_, err := converted.ConvertTo(4)
+		if err != nil {
+			return 0, err
+		}
+		return converted.WriteTo(w)
 	}
-	if tag.yearFrame == nil && tag2.yearFrame != nil {
-		tag.yearFrame = tag2.yearFrame
+	b, err := tag.Marshal(tag.version())
+	if err != nil {
+		return 0, err
 	}
+	n, err := w.Write(b)
+	return int64(n), err
 }
@@ -1,34 +1,99 @@
 package id3
 
+import (
+	"strings"
+)
+
+// TextFrame is one of the T*** text information frames. Since v2.4 a text
+// frame may hold several values separated by nulls; earlier versions
+// separate them with "/".
 type TextFrame struct {
 	frameBase
 
-	value string
+	values []string
 }
 
 func newTextFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
 	tf := &TextFrame{}
 	tf.header = header
-	val, err := readString(data)
+	if tag != nil && tag.Header != nil && tag.Header.version < 4 {
+		// Before v2.4 anything after the terminator is padding or junk
+		value, err := readText(data)
+		if err != nil {
+			return nil, err
+		}
+		tf.values = []string{value}
+		return tf, nil
+	}
+	values, err := readStrings(data)
 	if err != nil {
 		return nil, err
 	}
-	tf.value = val
+	for len(values) > 1 && values[len(values)-1] == "" {
+		values = values[:len(values)-1]
+	}
+	tf.values = values
 	return tf, nil
 }
 
-func simpleTextFrame(tag *Tag, id string, val string) Frame {
+// readText decodes the first string in a frame body that starts with a text
+// encoding byte.
+func readText(data []byte) (string, error) {
+	l := len(data)
+	if l < 2 {
+		return "", nil
+	}
+	textEncoding, encoding, err := extractEncoding(l, data)
+	if err != nil {
+		return "", err
+	}
+	data = data[1:]
+	if (textEncoding == UTF16 || textEncoding == UTF16BE) && len(data)%2 == 1 {
+		data = data[:len(data)-1]
+	}
+	s, _, err := trimForEncoding(len(data), data, textEncoding, false)
+	if err != nil {
+		return "", err
+	}
+	return decodeString(s, encoding)
+}
+
+func NewTextFrame(id string, values ...string) *TextFrame {
 	tf := &TextFrame{}
 
-	tf.header = newFrameHeader(id, 0, 0, uint32(len(val)))
-	tf.value = val
+	tf.header = newFrameHeader(id, 0, 0, 0)
+	tf.values = values
 	return tf
 }
 
+func simpleTextFrame(tag *Tag, id string, val string) Frame {
+	return NewTextFrame(id, val)
+}
+
+// String returns the values of the frame separated by "/".
 func (tf *TextFrame) String() string {
-	return tf.value
+	return strings.Join(tf.values, "/")
 }
 
 func (tf *TextFrame) Bytes() []byte {
-	return []byte(tf.value)
+	return []byte(tf.String())
+}
+
+func (tf *TextFrame) Values() []string {
+	values := make([]string, len(tf.values))
+	copy(values, tf.values)
+	return values
+}
+
+func (tf *TextFrame) SetValues(values ...string) {
+	tf.values = values
+}
+
+func (tf *TextFrame) encode(version uint8) ([]byte, error) {
+	if version < 4 {
+		value := tf.String()
+		textEncoding := encodingForVersion(version, value)
+		return appendString([]byte{byte(textEncoding)}, value, textEncoding, false)
+	}
+	return encodeStrings(version, tf.values)
 }
@@ -0,0 +1,256 @@
+package id3
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// EventType is the kind of an event in an ETCO frame.
+type EventType byte
+
+const (
+	EventPadding EventType = iota
+	EventEndOfInitialSilence
+	EventIntroStart
+	EventMainPartStart
+	EventOutroStart
+	EventOutroEnd
+	EventVerseStart
+	EventRefrainStart
+	EventInterludeStart
+	EventThemeStart
+	EventVariationStart
+	EventKeyChange
+	EventTimeChange
+	EventMomentaryUnwantedNoise
+	EventSustainedNoise
+	EventSustainedNoiseEnd
+	EventIntroEnd
+	EventMainPartEnd
+	EventVerseEnd
+	EventRefrainEnd
+	EventThemeEnd
+	EventProfanity
+	EventProfanityEnd
+)
+
+const (
+	// EventSync0 to EventSyncF are synchronisation points without a
+	// predefined meaning
+	EventSync0 EventType = 0xE0
+	EventSyncF EventType = 0xEF
+
+	EventAudioEnd     EventType = 0xFD
+	EventAudioFileEnd EventType = 0xFE
+	// EventMore is followed by another event byte, extending the event
+	// types beyond one byte. It is counted by TimedEvent.Extension rather
+	// than used as a type itself.
+	EventMore EventType = 0xFF
+)
+
+// TimedEvent is an event and the time it occurs, in the frame's timestamp
+// format.
+type TimedEvent struct {
+	Type      EventType
+	Timestamp uint32
+	// Extension is the number of EventMore bytes preceding Type
+	Extension int
+}
+
+// EventTimingCodesFrame is an ETCO frame, marking points of the audio such as
+// the start of the intro or the refrain.
+type EventTimingCodesFrame struct {
+	frameBase
+
+	timestampFormat TimestampFormat
+	events          []TimedEvent
+}
+
+func newEventTimingCodesFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	etf := &EventTimingCodesFrame{}
+	etf.header = header
+
+	if len(data) < 1 {
+		return nil, ErrTooShort
+	}
+	etf.timestampFormat = TimestampFormat(data[0])
+	for data = data[1:]; len(data) > 0; data = data[5:] {
+		var extension int
+		for len(data) > 0 && EventType(data[0]) == EventMore {
+			extension++
+			data = data[1:]
+		}
+		if len(data) < 5 {
+			return nil, ErrTooShort
+		}
+		etf.events = append(etf.events, TimedEvent{
+			Type:      EventType(data[0]),
+			Timestamp: binary.BigEndian.Uint32(data[1:]),
+			Extension: extension,
+		})
+	}
+	return etf, nil
+}
+
+func NewEventTimingCodesFrame(id string, timestampFormat TimestampFormat, events ...TimedEvent) *EventTimingCodesFrame {
+	etf := &EventTimingCodesFrame{}
+	etf.header = newFrameHeader(id, 0, 0, 0)
+	etf.timestampFormat = timestampFormat
+	etf.events = events
+	return etf
+}
+
+func (etf *EventTimingCodesFrame) TimestampFormat() TimestampFormat {
+	return etf.timestampFormat
+}
+
+func (etf *EventTimingCodesFrame) Events() []TimedEvent {
+	events := make([]TimedEvent, len(etf.events))
+	copy(events, etf.events)
+	return events
+}
+
+// Event returns the first event of the given type, if there is one.
+func (etf *EventTimingCodesFrame) Event(eventType EventType) (TimedEvent, bool) {
+	for _, event := range etf.events {
+		if event.Type == eventType {
+			return event, true
+		}
+	}
+	return TimedEvent{}, false
+}
+
+func (etf *EventTimingCodesFrame) String() string {
+	return fmt.Sprintf("%v events", len(etf.events))
+}
+
+func (etf *EventTimingCodesFrame) Bytes() []byte {
+	b, _ := etf.encode(4)
+	return b
+}
+
+func (etf *EventTimingCodesFrame) encode(version uint8) ([]byte, error) {
+	b := []byte{byte(etf.timestampFormat)}
+	for _, event := range etf.events {
+		if event.Type == EventMore {
+			return nil, ErrInvalidEvent
+		}
+		for i := 0; i < event.Extension; i++ {
+			b = append(b, byte(EventMore))
+		}
+		b = append(b, byte(event.Type), 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], event.Timestamp)
+	}
+	return b, nil
+}
+
+const (
+	// TempoBeatFree marks a part of the audio without a beat
+	TempoBeatFree uint16 = 0
+	// TempoSingleBeat marks a single beat followed by a beat-free part
+	TempoSingleBeat uint16 = 1
+
+	maxTempo uint16 = 0xFF + 0xFF
+)
+
+// TempoChange is the tempo, in beats per minute, from a point of the audio
+// on, in the frame's timestamp format.
+type TempoChange struct {
+	BPM       uint16
+	Timestamp uint32
+}
+
+// SynchronizedTempoCodesFrame is a SYTC frame, describing the tempo of the
+// audio as it changes.
+type SynchronizedTempoCodesFrame struct {
+	frameBase
+
+	timestampFormat TimestampFormat
+	changes         []TempoChange
+}
+
+func newSynchronizedTempoCodesFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	stf := &SynchronizedTempoCodesFrame{}
+	stf.header = header
+
+	if len(data) < 1 {
+		return nil, ErrTooShort
+	}
+	stf.timestampFormat = TimestampFormat(data[0])
+	for data = data[1:]; len(data) > 0; {
+		// Tempos from 255 on take a second byte, which is added
+		var bpm uint16
+		if data[0] == 0xFF {
+			if len(data) < 2 {
+				return nil, ErrTooShort
+			}
+			bpm = 0xFF
+			data = data[1:]
+		}
+		if len(data) < 5 {
+			return nil, ErrTooShort
+		}
+		stf.changes = append(stf.changes, TempoChange{
+			BPM:       bpm + uint16(data[0]),
+			Timestamp: binary.BigEndian.Uint32(data[1:]),
+		})
+		data = data[5:]
+	}
+	return stf, nil
+}
+
+func NewSynchronizedTempoCodesFrame(id string, timestampFormat TimestampFormat, changes ...TempoChange) *SynchronizedTempoCodesFrame {
+	stf := &SynchronizedTempoCodesFrame{}
+	stf.header = newFrameHeader(id, 0, 0, 0)
+	stf.timestampFormat = timestampFormat
+	stf.changes = changes
+	return stf
+}
+
+func (stf *SynchronizedTempoCodesFrame) TimestampFormat() TimestampFormat {
+	return stf.timestampFormat
+}
+
+func (stf *SynchronizedTempoCodesFrame) Changes() []TempoChange {
+	changes := make([]TempoChange, len(stf.changes))
+	copy(changes, stf.changes)
+	return changes
+}
+
+func (stf *SynchronizedTempoCodesFrame) String() string {
+	return fmt.Sprintf("%v tempo changes", len(stf.changes))
+}
+
+func (stf *SynchronizedTempoCodesFrame) Bytes() []byte {
+	b, _ := stf.encode(4)
+	return b
+}
+
+func (stf *SynchronizedTempoCodesFrame) encode(version uint8) ([]byte, error) {
+	b := []byte{byte(stf.timestampFormat)}
+	for _, change := range stf.changes {
+		switch {
+		case change.BPM > maxTempo:
+			return nil, ErrTooLarge
+		case change.BPM >= 0xFF:
+			b = append(b, 0xFF, byte(change.BPM-0xFF))
+		default:
+			b = append(b, byte(change.BPM))
+		}
+		b = append(b, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], change.Timestamp)
+	}
+	return b, nil
+}
+
+// EventTimingCodes returns the tag's ETCO frame, or nil.
+func (tag *Tag) EventTimingCodes() *EventTimingCodesFrame {
+	etf, _ := tag.Frame(FrameEventTimingCodes).(*EventTimingCodesFrame)
+	return etf
+}
+
+// SynchronizedTempoCodes returns the tag's SYTC frame, or nil.
+func (tag *Tag) SynchronizedTempoCodes() *SynchronizedTempoCodesFrame {
+	stf, _ := tag.Frame(FrameSynchronizedTempoCodes).(*SynchronizedTempoCodesFrame)
+	return stf
+}
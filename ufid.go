@@ -22,11 +22,13 @@ func newUniqueFileIDFrame(tag *Tag, header *frameHeader, data []byte) (Frame, er
 
 	var err error
 
-	owner, i, err := trimForEncoding(l, data, ISO88591, true)
+	owner, i, err := trimForEncoding(l, data, ISO88591, false)
 	if err != nil {
 		return nil, err
 	}
-	i++
+	if i > l {
+		return nil, ErrTooShort
+	}
 
 	uidf.owner, err = decodeString(owner, charmap.Windows1252)
 	if err != nil {
@@ -53,3 +55,11 @@ func (uidf *UniqueFileIDFrame) Owner() string {
 func (uidf *UniqueFileIDFrame) UniqueID() []byte {
 	return uidf.uniqueID
 }
+
+func (uidf *UniqueFileIDFrame) encode(version uint8) ([]byte, error) {
+	b, err := appendString(nil, uidf.owner, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, uidf.uniqueID...), nil
+}
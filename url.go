@@ -0,0 +1,130 @@
+package id3
+
+import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// URLFrame is one of the W*** link frames, whose body is a single
+// ISO-8859-1 URL.
+type URLFrame struct {
+	frameBase
+
+	url string
+}
+
+func newURLFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	uf := &URLFrame{}
+	uf.header = header
+	url, err := readURL(data)
+	if err != nil {
+		return nil, err
+	}
+	uf.url = url
+	return uf, nil
+}
+
+func NewURLFrame(id string, url string) *URLFrame {
+	uf := &URLFrame{}
+	uf.header = newFrameHeader(id, 0, 0, uint32(len(url)))
+	uf.url = url
+	return uf
+}
+
+func (uf *URLFrame) URL() string {
+	return uf.url
+}
+
+func (uf *URLFrame) String() string {
+	return uf.url
+}
+
+func (uf *URLFrame) Bytes() []byte {
+	return []byte(uf.url)
+}
+
+func (uf *URLFrame) encode(version uint8) ([]byte, error) {
+	return encodeString(uf.url, ISO88591)
+}
+
+// UserURLFrame is a WXXX frame: a URL along with a description in the
+// frame's text encoding.
+type UserURLFrame struct {
+	frameBase
+
+	description string
+	url         string
+}
+
+func newUserURLFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	uuf := &UserURLFrame{}
+	uuf.header = header
+
+	l := len(data)
+	if l < 1 {
+		return nil, ErrTooShort
+	}
+	textEncoding, encoding, err := extractEncoding(l, data)
+	if err != nil {
+		return nil, err
+	}
+	description, i, err := trimForEncoding(l, data, textEncoding, true)
+	if err != nil {
+		return nil, err
+	}
+	uuf.description, err = decodeString(description, encoding)
+	if err != nil {
+		return nil, err
+	}
+	if i < l {
+		uuf.url, err = readURL(data[i:])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return uuf, nil
+}
+
+func NewUserURLFrame(id string, description string, url string) *UserURLFrame {
+	uuf := &UserURLFrame{}
+	uuf.header = newFrameHeader(id, 0, 0, uint32(len(url)))
+	uuf.description = description
+	uuf.url = url
+	return uuf
+}
+
+func (uuf *UserURLFrame) Description() string {
+	return uuf.description
+}
+
+func (uuf *UserURLFrame) URL() string {
+	return uuf.url
+}
+
+func (uuf *UserURLFrame) String() string {
+	return fmt.Sprintf("%v (%v)", uuf.url, uuf.description)
+}
+
+func (uuf *UserURLFrame) Bytes() []byte {
+	return []byte(uuf.url)
+}
+
+func (uuf *UserURLFrame) encode(version uint8) ([]byte, error) {
+	textEncoding := encodingForVersion(version, uuf.description)
+	b, err := appendString([]byte{byte(textEncoding)}, uuf.description, textEncoding, true)
+	if err != nil {
+		return nil, err
+	}
+	return appendString(b, uuf.url, ISO88591, false)
+}
+
+// readURL decodes an ISO-8859-1 URL, ignoring the terminator some taggers
+// write after it.
+func readURL(data []byte) (string, error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	return decodeString(data, charmap.Windows1252)
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/hex"
 	"errors"
 	"io/ioutil"
+	"math"
+	"math/big"
 
 	"github.com/golang/glog"
 	"golang.org/x/text/encoding"
@@ -33,20 +35,17 @@ func unsafe(b []byte) uint32 {
 	return binary.BigEndian.Uint32(o)
 }
 
-func readString(data []byte) (string, error) {
-	l := len(data)
-	if l < 2 {
-		return "", nil
-	}
-	textEncoding, encoding, err := extractEncoding(l, data)
-	if err != nil {
-		return "", err
-	}
-	data, _, err = trimForEncoding(l, data, textEncoding, true)
-	if err != nil {
-		return "", err
+// resync reverses unsynchronisation by dropping the 0x00 inserted after every
+// 0xFF byte.
+func resync(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		out = append(out, data[i])
+		if data[i] == 0xFF && i+1 < len(data) && data[i+1] == 0x00 {
+			i++
+		}
 	}
-	return decodeString(data, encoding)
+	return out
 }
 
 func extractEncoding(l int, data []byte) (TextEncoding, encoding.Encoding, error) {
@@ -58,15 +57,10 @@ func extractEncoding(l int, data []byte) (TextEncoding, encoding.Encoding, error
 		// Technically a superset of ISO-8859-1, but we're only reading so it's ok
 		encoding = charmap.Windows1252
 	case UTF16:
-		if len(data) < 3 {
-			encoding = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
-		} else if data[1] == 0xFE && data[2] == 0xFF {
-			encoding = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
-		} else if data[1] == 0xFF && data[2] == 0xFE {
-			encoding = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
-		} else {
-			encoding = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
-		}
+		// Every string in the frame carries its own BOM, so let the decoder
+		// pick the byte order for each one. Strings without a BOM are read
+		// as big-endian.
+		encoding = unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
 	case UTF16BE:
 		encoding = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
 	case UTF8:
@@ -96,13 +90,31 @@ func trimForEncoding(l int, data []byte, textEncoding TextEncoding, strip bool)
 	case ISO88591, UTF8:
 		data, i = trimToNull(l, data, strip)
 	case UTF16, UTF16BE:
-		data, i = trimToDoubleNull(l, data, strip)
+		var err error
+		data, i, err = trimToDoubleNull(l, data, strip)
+		if err != nil {
+			return nil, 0, err
+		}
 	default:
 		return nil, 0, errors.New("unknown encoding")
 	}
 	return data, i, nil
 }
 
+// readLatin1String reads a null terminated ISO-8859-1 string and returns the
+// number of bytes it used.
+func readLatin1String(data []byte) (string, int, error) {
+	s, i, err := trimForEncoding(len(data), data, ISO88591, false)
+	if err != nil {
+		return "", 0, err
+	}
+	if i > len(data) {
+		return "", 0, ErrTooShort
+	}
+	value, err := decodeString(s, charmap.Windows1252)
+	return value, i, err
+}
+
 func trimToNull(l int, data []byte, strip bool) ([]byte, int) {
 	var i int
 	if strip {
@@ -120,19 +132,152 @@ func trimToNull(l int, data []byte, strip bool) ([]byte, int) {
 	return data[1:i], i + 1
 }
 
-func trimToDoubleNull(l int, data []byte, strip bool) ([]byte, int) {
+// trimToDoubleNull is trimToNull for UTF-16 text. It fails on a field that
+// ends in the middle of a character.
+func trimToDoubleNull(l int, data []byte, strip bool) ([]byte, int, error) {
 	var i int
 	if strip {
 		i = 1
 	}
 	for i < l {
+		if i+1 >= l {
+			return nil, 0, ErrTooShort
+		}
 		if data[i] == 0x0 && data[i+1] == 0x0 {
 			break
 		}
 		i += 2
 	}
 	if !strip {
-		return data[0:i], i + 2
+		return data[0:i], i + 2, nil
+	}
+	return data[1:i], i + 2, nil
+}
+
+func safe(n uint32) []byte {
+	return []byte{
+		byte(n>>21) & 0x7F,
+		byte(n>>14) & 0x7F,
+		byte(n>>7) & 0x7F,
+		byte(n) & 0x7F,
+	}
+}
+
+func encodingForVersion(version uint8, values ...string) TextEncoding {
+	for _, v := range values {
+		for _, r := range v {
+			if r > 0xFF {
+				if version >= 4 {
+					return UTF8
+				}
+				return UTF16
+			}
+		}
+	}
+	return ISO88591
+}
+
+func encodeString(s string, textEncoding TextEncoding) ([]byte, error) {
+	var encoder *encoding.Encoder
+	switch textEncoding {
+	case ISO88591:
+		encoder = charmap.ISO8859_1.NewEncoder()
+	case UTF16:
+		encoder = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder()
+	case UTF16BE:
+		encoder = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder()
+	case UTF8:
+		return []byte(s), nil
+	default:
+		return nil, errors.New("unknown encoding")
+	}
+	return encoder.Bytes([]byte(s))
+}
+
+func nullForEncoding(textEncoding TextEncoding) []byte {
+	switch textEncoding {
+	case UTF16, UTF16BE:
+		return []byte{0x0, 0x0}
+	}
+	return []byte{0x0}
+}
+
+func appendString(b []byte, s string, textEncoding TextEncoding, terminate bool) ([]byte, error) {
+	e, err := encodeString(s, textEncoding)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, e...)
+	if terminate {
+		b = append(b, nullForEncoding(textEncoding)...)
+	}
+	return b, nil
+}
+
+// readStrings decodes every null separated string in a frame body that starts
+// with a text encoding byte.
+func readStrings(data []byte) ([]string, error) {
+	l := len(data)
+	if l < 2 {
+		return nil, nil
+	}
+	textEncoding, encoding, err := extractEncoding(l, data)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	data = data[1:]
+	for len(data) > 0 {
+		if (textEncoding == UTF16 || textEncoding == UTF16BE) && len(data)%2 == 1 {
+			data = data[:len(data)-1]
+			continue
+		}
+		s, i, err := trimForEncoding(len(data), data, textEncoding, false)
+		if err != nil {
+			return nil, err
+		}
+		value, err := decodeString(s, encoding)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if i >= len(data) {
+			break
+		}
+		data = data[i:]
+	}
+	return values, nil
+}
+
+// encodeStrings builds a frame body holding null separated strings, preceded
+// by the text encoding byte.
+func encodeStrings(version uint8, values []string) ([]byte, error) {
+	textEncoding := encodingForVersion(version, values...)
+	b := []byte{byte(textEncoding)}
+	var err error
+	for i, value := range values {
+		b, err = appendString(b, value, textEncoding, i < len(values)-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// counterUint64 returns a counter as a uint64, saturating at math.MaxUint64.
+func counterUint64(n *big.Int) uint64 {
+	if !n.IsUint64() {
+		return math.MaxUint64
+	}
+	return n.Uint64()
+}
+
+// encodeCounter encodes a counter in the four bytes the spec requires, or as
+// many more as it needs.
+func encodeCounter(n *big.Int) []byte {
+	b := n.Bytes()
+	if len(b) < 4 {
+		b = append(make([]byte, 4-len(b)), b...)
 	}
-	return data[1:i], i + 2
+	return b
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -254,3 +255,41 @@ var (
 		"Psybient",
 	}
 )
+
+// parseGenres expands a genre value into genre names. v2.3 values may hold
+// references such as "(17)(21)" followed by a refinement, with "((" escaping a
+// leading parenthesis, and v2.4 values may be a bare number.
+func parseGenres(value string) []string {
+	var genres []string
+	add := func(genre string) {
+		if genre != "" && (len(genres) == 0 || genres[len(genres)-1] != genre) {
+			genres = append(genres, genre)
+		}
+	}
+	for strings.HasPrefix(value, "(") && !strings.HasPrefix(value, "((") {
+		end := strings.Index(value, ")")
+		if end < 0 {
+			break
+		}
+		add(genreName(value[1:end]))
+		value = value[end+1:]
+	}
+	if strings.HasPrefix(value, "((") {
+		value = value[1:]
+	}
+	add(genreName(value))
+	return genres
+}
+
+func genreName(ref string) string {
+	switch ref {
+	case "RX":
+		return "Remix"
+	case "CR":
+		return "Cover"
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 0 && n < len(v1Genres) {
+		return v1Genres[n]
+	}
+	return ref
+}
@@ -6,23 +6,23 @@ var version22Params = &versionParams{
 	frameFlagsSize: 0,
 	frames: map[string]*frameFactory{
 		"BUF": &frameFactory{description: "Recommended buffer size", maker: newDataFrame},
-		"CNT": &frameFactory{description: "Play counter", maker: newDataFrame},
+		"CNT": &frameFactory{description: "Play counter", maker: newPlayCounterFrame},
 		"COM": &frameFactory{description: "Comments", maker: newFullTextFrame},
-		"CRA": &frameFactory{description: "Audio encryption", maker: newDataFrame},
-		"CRM": &frameFactory{description: "Encrypted meta frame", maker: newDataFrame},
-		"ETC": &frameFactory{description: "Event timing codes", maker: newDataFrame},
+		"CRA": &frameFactory{description: "Audio encryption", maker: newAudioEncryptionFrame},
+		"CRM": &frameFactory{description: "Encrypted meta frame", maker: newEncryptedMetaFrame},
+		"ETC": &frameFactory{description: "Event timing codes", maker: newEventTimingCodesFrame},
 		"EQU": &frameFactory{description: "Equalization", maker: newDataFrame},
-		"GEO": &frameFactory{description: "General encapsulated object", maker: newDataFrame},
-		"IPL": &frameFactory{description: "Involved people list", maker: newDataFrame},
+		"GEO": &frameFactory{description: "General encapsulated object", maker: newGeneralObjectFrame},
+		"IPL": &frameFactory{description: "Involved people list", maker: newInvolvedPeopleFrame},
 		"LNK": &frameFactory{description: "Linked information", maker: newDataFrame},
-		"MCI": &frameFactory{description: "Music CD Identifier", maker: newDataFrame},
+		"MCI": &frameFactory{description: "Music CD Identifier", maker: newMCDIFrame},
 		"MLL": &frameFactory{description: "MPEG location lookup table", maker: newDataFrame},
 		"PIC": &frameFactory{description: "Attached picture", maker: newPictureFrame},
-		"POP": &frameFactory{description: "Popularimeter", maker: newDataFrame},
+		"POP": &frameFactory{description: "Popularimeter", maker: newPopularimeterFrame},
 		"REV": &frameFactory{description: "Reverb", maker: newDataFrame},
-		"RVA": &frameFactory{description: "Relative volume adjustment", maker: newDataFrame},
-		"SLT": &frameFactory{description: "Synchronized lyric/text", maker: newDataFrame},
-		"STC": &frameFactory{description: "Synced tempo codes", maker: newDataFrame},
+		"RVA": &frameFactory{description: "Relative volume adjustment", maker: newRelativeVolumeAdjustmentFrame},
+		"SLT": &frameFactory{description: "Synchronized lyric/text", maker: newSynchronizedLyricsFrame},
+		"STC": &frameFactory{description: "Synced tempo codes", maker: newSynchronizedTempoCodesFrame},
 		"TAL": &frameFactory{description: "Album/Movie/Show title", maker: newTextFrame},
 		"TBP": &frameFactory{description: "BPM (Beats Per Minute)", maker: newTextFrame},
 		"TCM": &frameFactory{description: "Composer", maker: newTextFrame},
@@ -61,12 +61,12 @@ var version22Params = &versionParams{
 		"TYE": &frameFactory{description: "Year", maker: newTextFrame},
 		"UFI": &frameFactory{description: "Unique file identifier", maker: newDataFrame},
 		"ULT": &frameFactory{description: "Unsychronized lyric/text transcription", maker: newDataFrame},
-		"WAF": &frameFactory{description: "Official audio file webpage", maker: newDataFrame},
-		"WAR": &frameFactory{description: "Official artist/performer webpage", maker: newDataFrame},
-		"WAS": &frameFactory{description: "Official audio source webpage", maker: newDataFrame},
-		"WCM": &frameFactory{description: "Commercial information", maker: newDataFrame},
-		"WCP": &frameFactory{description: "Copyright/Legal information", maker: newDataFrame},
-		"WPB": &frameFactory{description: "Publishers official webpage", maker: newDataFrame},
-		"WXX": &frameFactory{description: "User defined URL link frame", maker: newDataFrame},
+		"WAF": &frameFactory{description: "Official audio file webpage", maker: newURLFrame},
+		"WAR": &frameFactory{description: "Official artist/performer webpage", maker: newURLFrame},
+		"WAS": &frameFactory{description: "Official audio source webpage", maker: newURLFrame},
+		"WCM": &frameFactory{description: "Commercial information", maker: newURLFrame},
+		"WCP": &frameFactory{description: "Copyright/Legal information", maker: newURLFrame},
+		"WPB": &frameFactory{description: "Publishers official webpage", maker: newURLFrame},
+		"WXX": &frameFactory{description: "User defined URL link frame", maker: newUserURLFrame},
 	},
 }
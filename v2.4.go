@@ -6,34 +6,37 @@ var version24Params = &versionParams{
 	frameFlagsSize:     2,
 	sizeUnsynchronized: true,
 	frames: map[string]*frameFactory{
-		"AENC": &frameFactory{description: "Audio encryption", maker: newDataFrame},
+		"AENC": &frameFactory{description: "Audio encryption", maker: newAudioEncryptionFrame},
 		"APIC": &frameFactory{description: "Attached picture", maker: newPictureFrame},
+		"CHAP": &frameFactory{description: "Chapter", maker: newChapterFrame},
 		"COMM": &frameFactory{description: "Comments", maker: newFullTextFrame},
-		"COMR": &frameFactory{description: "Commercial frame", maker: newDataFrame},
-		"ENCR": &frameFactory{description: "Encryption method registration", maker: newDataFrame},
+		"COMR": &frameFactory{description: "Commercial frame", maker: newCommercialFrame},
+		"CTOC": &frameFactory{description: "Table of contents", maker: newTableOfContentsFrame},
+		"ENCR": &frameFactory{description: "Encryption method registration", maker: newRegistrationFrame},
 		"EQUA": &frameFactory{description: "Equalization", maker: newDataFrame},
-		"ETCO": &frameFactory{description: "Event timing codes", maker: newDataFrame},
-		"GEOB": &frameFactory{description: "General encapsulated object", maker: newDataFrame},
-		"GRID": &frameFactory{description: "Group identification registration", maker: newDataFrame},
-		"IPLS": &frameFactory{description: "Involved people list", maker: newDataFrame},
+		"ETCO": &frameFactory{description: "Event timing codes", maker: newEventTimingCodesFrame},
+		"GEOB": &frameFactory{description: "General encapsulated object", maker: newGeneralObjectFrame},
+		"GRID": &frameFactory{description: "Group identification registration", maker: newRegistrationFrame},
+		"IPLS": &frameFactory{description: "Involved people list", maker: newInvolvedPeopleFrame},
 		"LINK": &frameFactory{description: "Linked information", maker: newDataFrame},
-		"MCDI": &frameFactory{description: "Music CD identifier", maker: newDataFrame},
+		"MCDI": &frameFactory{description: "Music CD identifier", maker: newMCDIFrame},
 		"MJGN": &frameFactory{description: "Media Jukebox metadata", maker: newDataFrame},
 		"MJMD": &frameFactory{description: "Media Jukebox metadata", maker: newDataFrame},
 		"MLLT": &frameFactory{description: "MPEG location lookup table", maker: newDataFrame},
 		"NCON": &frameFactory{description: "MusicMatch", maker: newDataFrame},
-		"OWNE": &frameFactory{description: "Ownership frame", maker: newDataFrame},
-		"PRIV": &frameFactory{description: "Private frame", maker: newDataFrame},
-		"PCNT": &frameFactory{description: "Play counter", maker: newDataFrame},
-		"POPM": &frameFactory{description: "Popularimeter", maker: newDataFrame},
+		"OWNE": &frameFactory{description: "Ownership frame", maker: newOwnershipFrame},
+		"PRIV": &frameFactory{description: "Private frame", maker: newPrivateFrame},
+		"PCNT": &frameFactory{description: "Play counter", maker: newPlayCounterFrame},
+		"POPM": &frameFactory{description: "Popularimeter", maker: newPopularimeterFrame},
 		"POSS": &frameFactory{description: "Position synchronisation frame", maker: newDataFrame},
 		"RBUF": &frameFactory{description: "Recommended buffer size", maker: newDataFrame},
-		"RGAD": &frameFactory{description: "ReplayGain", maker: newDataFrame},
-		"RVAD": &frameFactory{description: "Relative volume adjustment", maker: newDataFrame},
-		"RVA2": &frameFactory{description: "Relative volume adjustment (2)", maker: newDataFrame},
+		"RGAD": &frameFactory{description: "ReplayGain", maker: newReplayGainFrame},
+		"RVAD": &frameFactory{description: "Relative volume adjustment", maker: newRelativeVolumeAdjustmentFrame},
+		"RVA2": &frameFactory{description: "Relative volume adjustment (2)", maker: newRelativeVolumeAdjustment2Frame},
 		"RVRB": &frameFactory{description: "Reverb", maker: newDataFrame},
-		"SYLT": &frameFactory{description: "Synchronized lyric/text", maker: newDataFrame},
-		"SYTC": &frameFactory{description: "Synchronized tempo codes", maker: newDataFrame},
+		"SEEK": &frameFactory{description: "Seek frame", maker: newSeekFrame},
+		"SYLT": &frameFactory{description: "Synchronized lyric/text", maker: newSynchronizedLyricsFrame},
+		"SYTC": &frameFactory{description: "Synchronized tempo codes", maker: newSynchronizedTempoCodesFrame},
 		"TALB": &frameFactory{description: "Album/Movie/Show title", maker: newTextFrame},
 		"TBPM": &frameFactory{description: "BPM (beats per minute)", maker: newTextFrame},
 		"TCOM": &frameFactory{description: "Composer", maker: newTextFrame},
@@ -50,7 +53,7 @@ var version24Params = &versionParams{
 		"TEXT": &frameFactory{description: "Lyricist/Text writer", maker: newTextFrame},
 		"TFLT": &frameFactory{description: "File type", maker: newTextFrame},
 		"TIME": &frameFactory{description: "Time", maker: newTextFrame},
-		"TIPL": &frameFactory{description: "Involved People List", maker: newTextFrame},
+		"TIPL": &frameFactory{description: "Involved People List", maker: newInvolvedPeopleFrame},
 		"TIT1": &frameFactory{description: "Content group description", maker: newTextFrame},
 		"TIT2": &frameFactory{description: "Title/songname/content description", maker: newTextFrame},
 		"TIT3": &frameFactory{description: "Subtitle/Description refinement", maker: newTextFrame},
@@ -58,7 +61,7 @@ var version24Params = &versionParams{
 		"TLAN": &frameFactory{description: "Language(s)", maker: newTextFrame},
 		"TLEN": &frameFactory{description: "Length", maker: newTextFrame},
 		"TMED": &frameFactory{description: "Media type", maker: newTextFrame},
-		"TMCL": &frameFactory{description: "Musicians Credit List", maker: newTextFrame},
+		"TMCL": &frameFactory{description: "Musicians Credit List", maker: newInvolvedPeopleFrame},
 		"TMOO": &frameFactory{description: "Mood", maker: newTextFrame},
 		"TOAL": &frameFactory{description: "Original album/movie/show title", maker: newTextFrame},
 		"TOFN": &frameFactory{description: "Original filename", maker: newTextFrame},
@@ -85,17 +88,17 @@ var version24Params = &versionParams{
 		"TYER": &frameFactory{description: "Year", maker: newTextFrame},
 		"TXXX": &frameFactory{description: "User defined text information frame", maker: newDescribedFrame},
 		"UFID": &frameFactory{description: "Unique file identifier", maker: newUniqueFileIDFrame},
-		"USER": &frameFactory{description: "Terms of use", maker: newDataFrame},
+		"USER": &frameFactory{description: "Terms of use", maker: newTermsOfUseFrame},
 		"TCMP": &frameFactory{description: "Part of a compilation (iTunes extension)", maker: newTextFrame},
 		"USLT": &frameFactory{description: "Unsychronized lyric/text transcription", maker: newFullTextFrame},
-		"WCOM": &frameFactory{description: "Commercial information", maker: newDataFrame},
-		"WCOP": &frameFactory{description: "Copyright/Legal information", maker: newDataFrame},
-		"WOAF": &frameFactory{description: "Official audio file webpage", maker: newDataFrame},
-		"WOAR": &frameFactory{description: "Official artist/performer webpage", maker: newDataFrame},
-		"WOAS": &frameFactory{description: "Official audio source webpage", maker: newDataFrame},
-		"WORS": &frameFactory{description: "Official internet radio station homepage", maker: newDataFrame},
-		"WPAY": &frameFactory{description: "Payment", maker: newDataFrame},
-		"WPUB": &frameFactory{description: "Publishers official webpage", maker: newDataFrame},
-		"WXXX": &frameFactory{description: "User defined URL link frame", maker: newDataFrame},
+		"WCOM": &frameFactory{description: "Commercial information", maker: newURLFrame},
+		"WCOP": &frameFactory{description: "Copyright/Legal information", maker: newURLFrame},
+		"WOAF": &frameFactory{description: "Official audio file webpage", maker: newURLFrame},
+		"WOAR": &frameFactory{description: "Official artist/performer webpage", maker: newURLFrame},
+		"WOAS": &frameFactory{description: "Official audio source webpage", maker: newURLFrame},
+		"WORS": &frameFactory{description: "Official internet radio station homepage", maker: newURLFrame},
+		"WPAY": &frameFactory{description: "Payment", maker: newURLFrame},
+		"WPUB": &frameFactory{description: "Publishers official webpage", maker: newURLFrame},
+		"WXXX": &frameFactory{description: "User defined URL link frame", maker: newUserURLFrame},
 		"XSOP": &frameFactory{description: "Performer sort order (MusicBrainz)", maker: newTextFrame},
 	}}
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 
@@ -13,6 +15,9 @@ import (
 const (
 	id3v23FrameSizeSize  uint32 = 4
 	id3v23FrameFlagsSize uint32 = 2
+
+	defaultPaddingSize uint32 = 1024
+	maxSynchsafeSize   uint32 = 0x0FFFFFFF
 )
 
 type frameFactory struct {
@@ -28,6 +33,18 @@ type versionParams struct {
 	frames             map[string]*frameFactory
 }
 
+func paramsForVersion(version uint8) (*versionParams, error) {
+	switch version {
+	case 2:
+		return version22Params, nil
+	case 3:
+		return version23Params, nil
+	case 4:
+		return version24Params, nil
+	}
+	return nil, errors.New(fmt.Sprintf("Unknown major revision: %v", version))
+}
+
 func (tag *Tag) readV2(framesSize uint32, params *versionParams, r io.ReadSeeker) error {
 	var i uint32
 	var n int
@@ -38,7 +55,7 @@ func (tag *Tag) readV2(framesSize uint32, params *versionParams, r io.ReadSeeker
 	frameFlags := make([]byte, params.frameFlagsSize)
 	for i < framesSize {
 
-		n, err = br.Read(frameId[:1])
+		n, err = io.ReadFull(br, frameId[:1])
 		if err != nil {
 			return err
 		}
@@ -52,18 +69,18 @@ func (tag *Tag) readV2(framesSize uint32, params *versionParams, r io.ReadSeeker
 			break
 		}
 		var statusFlags, formatFlags byte
-		n, err = br.Read(frameId[1:params.frameIdSize])
+		n, err = io.ReadFull(br, frameId[1:params.frameIdSize])
 		if err != nil || n != int(params.frameIdSize-1) {
 			return ErrTooShort
 		}
 		i += params.frameIdSize
-		n, err = br.Read(frameSize[0:params.frameSizeSize])
+		n, err = io.ReadFull(br, frameSize[0:params.frameSizeSize])
 		if err != nil || n != int(params.frameSizeSize) {
 			return ErrTooShort
 		}
 		i += params.frameSizeSize
 		if params.frameFlagsSize > 0 {
-			n, err = br.Read(frameFlags[0:params.frameFlagsSize])
+			n, err = io.ReadFull(br, frameFlags[0:params.frameFlagsSize])
 			if err != nil || n != int(params.frameFlagsSize) {
 				return ErrTooShort
 			}
@@ -89,7 +106,7 @@ func (tag *Tag) readV2(framesSize uint32, params *versionParams, r io.ReadSeeker
 			return ErrTooShort
 		}
 		data := make([]byte, frameLength)
-		n, err = br.Read(data)
+		n, err = io.ReadFull(br, data)
 		if err != nil || n != int(frameLength) {
 			return ErrTooShort
 		}
@@ -103,7 +120,18 @@ func (tag *Tag) readV2(framesSize uint32, params *versionParams, r io.ReadSeeker
 			glog.Errorf("DATA: %v", hex.EncodeToString(data))
 			continue
 		}
-		frame, err := factory.maker(tag, newFrameHeader(string(frameId), statusFlags, formatFlags, frameLength), data)
+		header := newFrameHeader(string(frameId), statusFlags, formatFlags, frameLength)
+		data, err = header.readFormat(tag.Header.version, tag.Header.Unsynchronized(), data)
+		if err != nil {
+			glog.Errorf("Error reading tag %v: %v", string(frameId[:]), err)
+			continue
+		}
+		maker := factory.maker
+		if header.encrypted {
+			// Encrypted frames are kept as they are until decrypted
+			maker = newEncryptedFrame
+		}
+		frame, err := maker(tag, header, data)
 		if err != nil {
 			glog.Errorf("Error parsing tag %v: %v", string(frameId[:]), err)
 			glog.Errorf("DATA: %v", hex.EncodeToString(data))
@@ -136,3 +164,67 @@ func (tag *Tag) readV2(framesSize uint32, params *versionParams, r io.ReadSeeker
 	}
 	return nil
 }
+
+func (tag *Tag) writeV2(version uint8, padding uint32, params *versionParams) ([]byte, error) {
+	var fromVersion uint8
+	if tag.Header != nil {
+		fromVersion = tag.Header.version
+	}
+	b, err := writeFrames(make([]byte, headerSize), tag.frames, fromVersion, version, params)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, make([]byte, padding)...)
+
+	size := uint32(len(b)) - headerSize
+	if size > maxSynchsafeSize {
+		return nil, ErrTooLarge
+	}
+	copy(b, "ID3")
+	b[3] = version
+	b[4] = 0
+	b[5] = 0
+	copy(b[6:headerSize], safe(size))
+	return b, nil
+}
+
+// writeFrames appends the encoded frames, headers included, to b. It is used
+// for the frames of a tag and for the sub-frames embedded in chapters.
+func writeFrames(b []byte, frames []Frame, fromVersion uint8, version uint8, params *versionParams) ([]byte, error) {
+	for _, frame := range frames {
+		id := frame.Id()
+		if uint32(len(id)) != params.frameIdSize {
+			return nil, errors.New(fmt.Sprintf("Frame %v cannot be written to a v2.%v tag", id, version))
+		}
+		data, err := frame.encode(version)
+		if err != nil {
+			return nil, err
+		}
+		formatFlags, data, err := frame.base().header.writeFormat(version, data)
+		if err != nil {
+			return nil, err
+		}
+		frameLength := uint32(len(data))
+		b = append(b, id...)
+		switch {
+		case params.sizeUnsynchronized:
+			if frameLength > maxSynchsafeSize {
+				return nil, ErrTooLarge
+			}
+			b = append(b, safe(frameLength)...)
+		case params.frameSizeSize == 4:
+			b = append(b, 0, 0, 0, 0)
+			binary.BigEndian.PutUint32(b[len(b)-4:], frameLength)
+		default:
+			if frameLength > 0xFFFFFF {
+				return nil, ErrTooLarge
+			}
+			b = append(b, byte(frameLength>>16), byte(frameLength>>8), byte(frameLength))
+		}
+		if params.frameFlagsSize > 0 {
+			b = append(b, convertStatusFlags(frame.StatusFlags(), fromVersion, version), formatFlags)
+		}
+		b = append(b, data...)
+	}
+	return b, nil
+}
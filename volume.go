@@ -0,0 +1,458 @@
+package id3
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ChannelType identifies the channel a volume adjustment applies to.
+type ChannelType byte
+
+const (
+	ChannelOther ChannelType = iota
+	ChannelMaster
+	ChannelFrontRight
+	ChannelFrontLeft
+	ChannelBackRight
+	ChannelBackLeft
+	ChannelFrontCentre
+	ChannelBackCentre
+	ChannelSubwoofer
+)
+
+// VolumeAdjustment is the adjustment of one channel of an RVA2 frame, in
+// decibels, and its peak volume where 1 is full scale.
+type VolumeAdjustment struct {
+	Channel    ChannelType
+	Adjustment float64
+	Peak       float64
+}
+
+// RelativeVolumeAdjustment2Frame is a v2.4 RVA2 frame. The identification
+// tells apart several adjustments, such as "track" and "album" ReplayGain.
+type RelativeVolumeAdjustment2Frame struct {
+	frameBase
+
+	identification string
+	channels       []VolumeAdjustment
+}
+
+func newRelativeVolumeAdjustment2Frame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	rvf := &RelativeVolumeAdjustment2Frame{}
+	rvf.header = header
+
+	identification, i, err := trimForEncoding(len(data), data, ISO88591, false)
+	if err != nil {
+		return nil, err
+	}
+	if i > len(data) {
+		return nil, ErrTooShort
+	}
+	rvf.identification, err = decodeString(identification, charmap.Windows1252)
+	if err != nil {
+		return nil, err
+	}
+	for data = data[i:]; len(data) > 0; {
+		if len(data) < 4 {
+			return nil, ErrTooShort
+		}
+		va := VolumeAdjustment{
+			Channel:    ChannelType(data[0]),
+			Adjustment: float64(int16(binary.BigEndian.Uint16(data[1:]))) / 512,
+		}
+		bits := int(data[3])
+		if bits > 64 {
+			return nil, ErrVolumeWidth
+		}
+		n := (bits + 7) / 8
+		if len(data) < 4+n {
+			return nil, ErrTooShort
+		}
+		if bits > 0 {
+			var peak uint64
+			for _, b := range data[4 : 4+n] {
+				peak = peak<<8 | uint64(b)
+			}
+			va.Peak = float64(peak) / math.Pow(2, float64(bits-1))
+		}
+		rvf.channels = append(rvf.channels, va)
+		data = data[4+n:]
+	}
+	return rvf, nil
+}
+
+func NewRelativeVolumeAdjustment2Frame(id string, identification string, channels ...VolumeAdjustment) *RelativeVolumeAdjustment2Frame {
+	rvf := &RelativeVolumeAdjustment2Frame{}
+	rvf.header = newFrameHeader(id, 0, 0, 0)
+	rvf.identification = identification
+	rvf.channels = channels
+	return rvf
+}
+
+func (rvf *RelativeVolumeAdjustment2Frame) Identification() string {
+	return rvf.identification
+}
+
+func (rvf *RelativeVolumeAdjustment2Frame) Channels() []VolumeAdjustment {
+	channels := make([]VolumeAdjustment, len(rvf.channels))
+	copy(channels, rvf.channels)
+	return channels
+}
+
+// Channel returns the adjustment of the given channel, if the frame has one.
+func (rvf *RelativeVolumeAdjustment2Frame) Channel(channel ChannelType) (VolumeAdjustment, bool) {
+	for _, va := range rvf.channels {
+		if va.Channel == channel {
+			return va, true
+		}
+	}
+	return VolumeAdjustment{}, false
+}
+
+func (rvf *RelativeVolumeAdjustment2Frame) String() string {
+	var adjustments []string
+	for _, va := range rvf.channels {
+		adjustments = append(adjustments, fmt.Sprintf("%v: %+.2f dB", va.Channel, va.Adjustment))
+	}
+	return fmt.Sprintf("%v (%v)", rvf.identification, strings.Join(adjustments, ", "))
+}
+
+func (rvf *RelativeVolumeAdjustment2Frame) Bytes() []byte {
+	b, _ := rvf.encode(4)
+	return b
+}
+
+func (rvf *RelativeVolumeAdjustment2Frame) encode(version uint8) ([]byte, error) {
+	b, err := appendString(nil, rvf.identification, ISO88591, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, va := range rvf.channels {
+		adjustment := math.Floor(va.Adjustment*512 + 0.5)
+		if adjustment < math.MinInt16 || adjustment > math.MaxInt16 {
+			return nil, ErrTooLarge
+		}
+		b = append(b, byte(va.Channel), 0, 0)
+		binary.BigEndian.PutUint16(b[len(b)-2:], uint16(int16(adjustment)))
+		if va.Peak <= 0 {
+			b = append(b, 0)
+			continue
+		}
+		// Peaks are written with 16 bits, so full scale is 0x8000
+		peak := math.Floor(va.Peak*32768 + 0.5)
+		if peak > 0xFFFF {
+			peak = 0xFFFF
+		}
+		b = append(b, 16, byte(uint16(peak)>>8), byte(peak))
+	}
+	return b, nil
+}
+
+// VolumeChange is the change of one channel of an RVAD frame. The change and
+// peak are raw values of the frame's bit width; their scale is not defined by
+// the specification.
+type VolumeChange struct {
+	Channel ChannelType
+	Change  int64
+	Peak    uint64
+}
+
+// rvadChannels are the channels of an RVAD frame in the order they are
+// stored, along with the increment flag bit of each.
+var rvadChannels = []struct {
+	channel ChannelType
+	flag    byte
+}{
+	{ChannelFrontRight, 0x01},
+	{ChannelFrontLeft, 0x02},
+	{ChannelBackRight, 0x04},
+	{ChannelBackLeft, 0x08},
+	{ChannelFrontCentre, 0x10},
+	{ChannelSubwoofer, 0x20},
+}
+
+// RelativeVolumeAdjustmentFrame is the RVAD frame of v2.3, or RVA in v2.2,
+// which RVA2 replaced.
+type RelativeVolumeAdjustmentFrame struct {
+	frameBase
+
+	bits     byte
+	channels []VolumeChange
+}
+
+func newRelativeVolumeAdjustmentFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	rvf := &RelativeVolumeAdjustmentFrame{}
+	rvf.header = header
+
+	if len(data) < 2 || data[1] == 0 {
+		return nil, ErrTooShort
+	}
+	flags := data[0]
+	rvf.bits = data[1]
+	if rvf.bits > 64 {
+		return nil, ErrVolumeWidth
+	}
+	n := (int(rvf.bits) + 7) / 8
+	data = data[2:]
+	read := func() uint64 {
+		var v uint64
+		for _, b := range data[:n] {
+			v = v<<8 | uint64(b)
+		}
+		data = data[n:]
+		return v
+	}
+	// Channels come in groups: right and left, then back right and back
+	// left, each followed by their peaks, then centre and bass with theirs
+	for _, group := range [][]int{{0, 1}, {2, 3}, {4}, {5}} {
+		if len(data) < 2*n*len(group) {
+			break
+		}
+		changes := make([]VolumeChange, len(group))
+		for i, c := range group {
+			change := read()
+			if change > math.MaxInt64 {
+				return nil, ErrVolumeWidth
+			}
+			changes[i].Channel = rvadChannels[c].channel
+			changes[i].Change = int64(change)
+			if flags&rvadChannels[c].flag == 0 {
+				changes[i].Change = -changes[i].Change
+			}
+		}
+		for i := range group {
+			changes[i].Peak = read()
+		}
+		rvf.channels = append(rvf.channels, changes...)
+	}
+	return rvf, nil
+}
+
+func (rvf *RelativeVolumeAdjustmentFrame) Bits() byte {
+	return rvf.bits
+}
+
+func (rvf *RelativeVolumeAdjustmentFrame) Channels() []VolumeChange {
+	channels := make([]VolumeChange, len(rvf.channels))
+	copy(channels, rvf.channels)
+	return channels
+}
+
+func (rvf *RelativeVolumeAdjustmentFrame) String() string {
+	var changes []string
+	for _, vc := range rvf.channels {
+		changes = append(changes, fmt.Sprintf("%v: %+d", vc.Channel, vc.Change))
+	}
+	return strings.Join(changes, ", ")
+}
+
+func (rvf *RelativeVolumeAdjustmentFrame) Bytes() []byte {
+	b, _ := rvf.encode(3)
+	return b
+}
+
+func (rvf *RelativeVolumeAdjustmentFrame) encode(version uint8) ([]byte, error) {
+	n := (int(rvf.bits) + 7) / 8
+	if n == 0 || n > 8 {
+		return nil, ErrTooLarge
+	}
+	var flags byte
+	values := make([]uint64, 2*len(rvadChannels))
+	last := -1
+	for _, vc := range rvf.channels {
+		for i, c := range rvadChannels {
+			if c.channel != vc.Channel {
+				continue
+			}
+			change := vc.Change
+			if change >= 0 {
+				flags |= c.flag
+			} else {
+				change = -change
+			}
+			values[2*i], values[2*i+1] = uint64(change), vc.Peak
+			if i > last {
+				last = i
+			}
+		}
+	}
+	b := []byte{flags, rvf.bits}
+	put := func(v uint64) {
+		for i := n - 1; i >= 0; i-- {
+			b = append(b, byte(v>>(8*uint(i))))
+		}
+	}
+	for _, group := range [][]int{{0, 1}, {2, 3}, {4}, {5}} {
+		if group[0] > last && group[0] > 1 {
+			break
+		}
+		for _, c := range group {
+			put(values[2*c])
+		}
+		for _, c := range group {
+			put(values[2*c+1])
+		}
+	}
+	return b, nil
+}
+
+// ReplayGainFrame is the non-standard RGAD frame, holding the peak amplitude
+// and the radio (track) and audiophile (album) gain adjustments.
+type ReplayGainFrame struct {
+	frameBase
+
+	peak       float32
+	trackGain  float64
+	albumGain  float64
+	hasTrack   bool
+	hasAlbum   bool
+	originator [2]byte
+}
+
+const (
+	rgadNameRadio      = 1
+	rgadNameAudiophile = 2
+)
+
+func newReplayGainFrame(tag *Tag, header *frameHeader, data []byte) (Frame, error) {
+	rgf := &ReplayGainFrame{}
+	rgf.header = header
+
+	if len(data) < 8 {
+		return nil, ErrTooShort
+	}
+	rgf.peak = math.Float32frombits(binary.BigEndian.Uint32(data))
+	for i := 0; i < 2; i++ {
+		adjustment := binary.BigEndian.Uint16(data[4+2*i:])
+		gain := float64(adjustment&0x01FF) / 10
+		if adjustment&0x0200 != 0 {
+			gain = -gain
+		}
+		switch adjustment >> 13 {
+		case rgadNameRadio:
+			rgf.trackGain, rgf.hasTrack = gain, true
+			rgf.originator[0] = byte(adjustment>>10) & 0x07
+		case rgadNameAudiophile:
+			rgf.albumGain, rgf.hasAlbum = gain, true
+			rgf.originator[1] = byte(adjustment>>10) & 0x07
+		}
+	}
+	return rgf, nil
+}
+
+func (rgf *ReplayGainFrame) Peak() float64 {
+	return float64(rgf.peak)
+}
+
+func (rgf *ReplayGainFrame) TrackGain() (float64, bool) {
+	return rgf.trackGain, rgf.hasTrack
+}
+
+func (rgf *ReplayGainFrame) AlbumGain() (float64, bool) {
+	return rgf.albumGain, rgf.hasAlbum
+}
+
+func (rgf *ReplayGainFrame) String() string {
+	return fmt.Sprintf("track %+.1f dB, album %+.1f dB, peak %v", rgf.trackGain, rgf.albumGain, rgf.peak)
+}
+
+func (rgf *ReplayGainFrame) Bytes() []byte {
+	b, _ := rgf.encode(3)
+	return b
+}
+
+func (rgf *ReplayGainFrame) encode(version uint8) ([]byte, error) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b, math.Float32bits(rgf.peak))
+	adjustment := func(name uint16, originator byte, gain float64) uint16 {
+		v := uint16(name)<<13 | uint16(originator&0x07)<<10
+		if gain < 0 {
+			v |= 0x0200
+			gain = -gain
+		}
+		return v | uint16(math.Min(math.Floor(gain*10+0.5), 0x01FF))
+	}
+	if rgf.hasTrack {
+		binary.BigEndian.PutUint16(b[4:], adjustment(rgadNameRadio, rgf.originator[0], rgf.trackGain))
+	}
+	if rgf.hasAlbum {
+		binary.BigEndian.PutUint16(b[6:], adjustment(rgadNameAudiophile, rgf.originator[1], rgf.albumGain))
+	}
+	return b, nil
+}
+
+// ReplayGain holds the gain, in decibels, and the peak, where 1 is full
+// scale, for a track or an album. A peak of 0 means it is unknown.
+type ReplayGain struct {
+	Gain float64
+	Peak float64
+}
+
+// ReplayGain returns the track and album ReplayGain of the tag, either of
+// which may be nil. They are read from the TXXX frames foobar2000 and others
+// write, from RVA2 frames identified as "track" or "album", or from RGAD, in
+// that order of preference.
+func (tag *Tag) ReplayGain() (track *ReplayGain, album *ReplayGain) {
+	for _, frame := range tag.framesOf(FrameUserText) {
+		ftf, ok := frame.(*FullTextFrame)
+		if !ok {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(ftf.String()), "dB"))
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		switch strings.ToUpper(ftf.Description()) {
+		case "REPLAYGAIN_TRACK_GAIN":
+			track = replayGainOf(track)
+			track.Gain = n
+		case "REPLAYGAIN_TRACK_PEAK":
+			track = replayGainOf(track)
+			track.Peak = n
+		case "REPLAYGAIN_ALBUM_GAIN":
+			album = replayGainOf(album)
+			album.Gain = n
+		case "REPLAYGAIN_ALBUM_PEAK":
+			album = replayGainOf(album)
+			album.Peak = n
+		}
+	}
+	for _, frame := range tag.framesOf(FrameRelativeVolumeAdjustment2) {
+		rvf, ok := frame.(*RelativeVolumeAdjustment2Frame)
+		if !ok {
+			continue
+		}
+		va, ok := rvf.Channel(ChannelMaster)
+		if !ok {
+			continue
+		}
+		switch {
+		case track == nil && strings.EqualFold(rvf.Identification(), "track"):
+			track = &ReplayGain{va.Adjustment, va.Peak}
+		case album == nil && strings.EqualFold(rvf.Identification(), "album"):
+			album = &ReplayGain{va.Adjustment, va.Peak}
+		}
+	}
+	if rgf, ok := tag.Frame(FrameReplayGain).(*ReplayGainFrame); ok {
+		if gain, ok := rgf.TrackGain(); ok && track == nil {
+			track = &ReplayGain{gain, rgf.Peak()}
+		}
+		if gain, ok := rgf.AlbumGain(); ok && album == nil {
+			album = &ReplayGain{Gain: gain}
+		}
+	}
+	return track, album
+}
+
+func replayGainOf(rg *ReplayGain) *ReplayGain {
+	if rg == nil {
+		return &ReplayGain{}
+	}
+	return rg
+}